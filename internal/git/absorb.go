@@ -0,0 +1,391 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// absorbMaxCommits limits how far back absorb looks for target commits.
+const absorbMaxCommits = 50
+
+// blameHeaderRegex matches the per-line headers of `git blame --porcelain`.
+var blameHeaderRegex = regexp.MustCompile(`^([0-9a-f]{40}) \d+ \d+`)
+
+// AbsorbCommit is a commit that staged hunks can be absorbed into.
+type AbsorbCommit struct {
+	SHA     string
+	Subject string
+}
+
+// AbsorbHunk is a single staged hunk and the commits it could be folded into.
+type AbsorbHunk struct {
+	Path   string
+	Header string   // The "@@ ... @@" line.
+	Lines  []string // The removed and added lines, with their -/+ prefix.
+	// Candidates are the unpushed commits that last touched the lines of the
+	// hunk, most recent first.
+	Candidates []string
+	// Target is the commit the hunk will be absorbed into. An empty target
+	// leaves the hunk staged.
+	Target string
+	// Ambiguous is set when the target is a guess: the lines were last
+	// touched by several commits, some of them already pushed, or the hunk
+	// only adds lines.
+	Ambiguous bool
+
+	oldStart int
+	oldCount int
+	newLines []string
+}
+
+// AbsorbPlan describes how staged hunks will be turned into fixup commits.
+type AbsorbPlan struct {
+	Hunks   []*AbsorbHunk
+	Commits map[string]AbsorbCommit // Candidate commits by full SHA.
+	order   []string                // Candidate SHAs, most recent first.
+}
+
+// PlanAbsorb finds, for every staged hunk, the most recent commit on the
+// current branch that is not yet in the upstream and last touched the lines
+// the hunk changes. New, deleted, renamed and binary files are not absorbed.
+func (g *GitCommands) PlanAbsorb() (*AbsorbPlan, error) {
+	plan, err := g.absorbCandidates()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := ExecCommand("git", "diff", "--cached", "-U0", "--no-color", "--no-ext-diff", "--no-renames").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged changes: %v", err)
+	}
+	hunks := parseZeroContextDiff(string(output))
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("no staged changes to absorb")
+	}
+
+	for _, hunk := range hunks {
+		blamed, err := g.blameHunk(hunk)
+		if err != nil {
+			return nil, err
+		}
+		allCandidates := true
+		for _, sha := range blamed {
+			if _, ok := plan.Commits[sha]; !ok {
+				allCandidates = false
+			}
+		}
+		for _, sha := range plan.order {
+			if containsSHA(blamed, sha) {
+				hunk.Candidates = append(hunk.Candidates, sha)
+			}
+		}
+		if len(hunk.Candidates) > 0 {
+			hunk.Target = hunk.Candidates[0]
+		}
+		hunk.Ambiguous = len(hunk.Candidates) > 1 ||
+			(len(hunk.Candidates) == 1 && (!allCandidates || hunk.oldCount == 0))
+		plan.Hunks = append(plan.Hunks, hunk)
+	}
+
+	return plan, nil
+}
+
+// ApplyAbsorb creates a fixup commit for every target in the plan and then
+// folds them in with an autosquash rebase. Hunks without a target stay staged.
+func (g *GitCommands) ApplyAbsorb(plan *AbsorbPlan) (string, error) {
+	// Group the hunks by target, oldest target first.
+	var targets []string
+	byTarget := make(map[string][]*AbsorbHunk)
+	for _, hunk := range plan.Hunks {
+		if hunk.Target == "" {
+			continue
+		}
+		if _, ok := byTarget[hunk.Target]; !ok {
+			targets = append(targets, hunk.Target)
+		}
+		byTarget[hunk.Target] = append(byTarget[hunk.Target], hunk)
+	}
+	if len(targets) == 0 {
+		return "", fmt.Errorf("no hunks selected to absorb")
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return plan.position(targets[i]) > plan.position(targets[j])
+	})
+
+	// The fixup commits are made on top of HEAD without touching the index
+	// or working tree, so moving HEAD back is all it takes to undo them.
+	head, err := g.ResolveCommit("HEAD")
+	if err != nil {
+		return "", err
+	}
+	rollback := func() {
+		_ = ExecCommand("git", "update-ref", "-m", "gitx: undo absorb", "HEAD", head).Run()
+	}
+
+	// The hunks were computed against the original HEAD, so every file is
+	// rebuilt from its original content plus all hunks absorbed so far.
+	original := make(map[string][]string)
+	applied := make(map[string][]*AbsorbHunk)
+	for _, target := range targets {
+		changed := make(map[string][]string)
+		for _, hunk := range byTarget[target] {
+			if _, ok := original[hunk.Path]; !ok {
+				content, err := ExecCommand("git", "show", "HEAD:"+hunk.Path).Output()
+				if err != nil {
+					rollback()
+					return "", fmt.Errorf("failed to read %s: %v", hunk.Path, err)
+				}
+				original[hunk.Path] = splitLinesKeepEnds(string(content))
+			}
+			applied[hunk.Path] = append(applied[hunk.Path], hunk)
+			changed[hunk.Path] = applyHunks(original[hunk.Path], applied[hunk.Path])
+		}
+		if err := g.commitFixupTree(plan.Commits[target], changed); err != nil {
+			rollback()
+			return "", err
+		}
+	}
+
+	output, err := g.Autosquash(targets[0])
+	if err != nil {
+		rollback()
+	}
+	return output, err
+}
+
+// position returns the index of sha in the candidate order (0 is newest).
+func (p *AbsorbPlan) position(sha string) int {
+	for i, candidate := range p.order {
+		if candidate == sha {
+			return i
+		}
+	}
+	return len(p.order)
+}
+
+// absorbCandidates lists the commits on the current branch that are not in
+// its upstream, or not on any remote branch when there is no upstream.
+func (g *GitCommands) absorbCandidates() (*AbsorbPlan, error) {
+	args := []string{"log", "--no-merges", fmt.Sprintf("--max-count=%d", absorbMaxCommits), "--format=%H%x09%s", "HEAD", "--not"}
+	output, err := ExecCommand("git", append(args, "@{upstream}")...).Output()
+	if err != nil {
+		output, err = ExecCommand("git", append(args, "--remotes")...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list unpushed commits: %v", err)
+		}
+	}
+
+	plan := &AbsorbPlan{Commits: make(map[string]AbsorbCommit)}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		sha, subject, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		plan.Commits[sha] = AbsorbCommit{SHA: sha, Subject: subject}
+		plan.order = append(plan.order, sha)
+	}
+	if len(plan.order) == 0 {
+		return nil, fmt.Errorf("there are no unpushed commits to absorb into")
+	}
+	return plan, nil
+}
+
+// blameHunk returns the commits that last touched the lines a hunk changes.
+// For hunks that only add lines, the surrounding lines are blamed instead.
+func (g *GitCommands) blameHunk(hunk *AbsorbHunk) ([]string, error) {
+	start, end := hunk.oldStart, hunk.oldStart+hunk.oldCount-1
+	if hunk.oldCount == 0 {
+		// "-12,0" means the lines were inserted after line 12.
+		start, end = hunk.oldStart, hunk.oldStart+1
+		if start < 1 {
+			start = 1
+		}
+		if lines := g.countLines(hunk.Path); end > lines {
+			end = lines
+		}
+		if start > end {
+			return nil, nil
+		}
+	}
+
+	cmd := ExecCommand("git", "blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", start, end), "HEAD", "--", hunk.Path)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame %s: %v: %s", hunk.Path, err, strings.TrimSpace(string(output)))
+	}
+
+	var shas []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if match := blameHeaderRegex.FindStringSubmatch(line); match != nil && !containsSHA(shas, match[1]) {
+			shas = append(shas, match[1])
+		}
+	}
+	return shas, nil
+}
+
+// countLines returns the number of lines of a file in HEAD.
+func (g *GitCommands) countLines(path string) int {
+	content, err := ExecCommand("git", "show", "HEAD:"+path).Output()
+	if err != nil {
+		return 0
+	}
+	return len(splitLinesKeepEnds(string(content)))
+}
+
+// commitFixupTree commits the given file contents on top of HEAD as a
+// "fixup!" commit for target. A temporary index is used so that the real
+// index and working tree are left untouched.
+func (g *GitCommands) commitFixupTree(target AbsorbCommit, files map[string][]string) error {
+	indexFile, err := writeTempFile("gitx-absorb-index-", "")
+	if err != nil {
+		return err
+	}
+	// read-tree refuses to read into an empty file, so let git create it.
+	_ = os.Remove(indexFile)
+	defer func() {
+		_ = os.Remove(indexFile)
+	}()
+	env := append(os.Environ(), "GIT_INDEX_FILE="+indexFile)
+
+	run := func(stdin string, args ...string) (string, error) {
+		cmd := ExecCommand("git", args...)
+		cmd.Env = env
+		if stdin != "" {
+			cmd.Stdin = strings.NewReader(stdin)
+		}
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("git %s failed: %v", args[0], err)
+		}
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	if _, err := run("", "read-tree", "HEAD"); err != nil {
+		return err
+	}
+	for path, lines := range files {
+		entry, err := run("", "ls-tree", "HEAD", "--", path)
+		if err != nil || entry == "" {
+			return fmt.Errorf("failed to find %s in HEAD", path)
+		}
+		mode := strings.Fields(entry)[0]
+		blob, err := g.hashObject(strings.Join(lines, ""), env)
+		if err != nil {
+			return err
+		}
+		if _, err := run("", "update-index", "--cacheinfo", fmt.Sprintf("%s,%s,%s", mode, blob, path)); err != nil {
+			return err
+		}
+	}
+
+	tree, err := run("", "write-tree")
+	if err != nil {
+		return err
+	}
+	parent, err := run("", "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	commit, err := run("", "commit-tree", tree, "-p", parent, "-m", "fixup! "+target.Subject)
+	if err != nil {
+		return err
+	}
+	_, err = run("", "update-ref", "-m", "gitx: absorb into "+target.SHA[:7], "HEAD", commit, parent)
+	return err
+}
+
+// hashObject writes content to the object database and returns its blob SHA.
+func (g *GitCommands) hashObject(content string, env []string) (string, error) {
+	cmd := ExecCommand("git", "hash-object", "-w", "--stdin")
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(content)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to write blob: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// parseZeroContextDiff parses the output of `git diff -U0` into hunks,
+// skipping files that are added, deleted or binary.
+func parseZeroContextDiff(diff string) []*AbsorbHunk {
+	var hunks []*AbsorbHunk
+	for _, file := range ParseDiff(diff).Files {
+		if file.IsNew || file.Deleted || file.Binary {
+			continue
+		}
+		for _, diffHunk := range file.Hunks {
+			hunk := &AbsorbHunk{
+				Path:     file.Path(),
+				Header:   diffHunk.Header,
+				oldStart: diffHunk.OldStart,
+				oldCount: diffHunk.OldCount,
+			}
+			for _, line := range diffHunk.Lines {
+				switch line.Kind {
+				case DiffAdded:
+					hunk.Lines = append(hunk.Lines, "+"+line.Content)
+					newLine := line.Content + "\n"
+					if line.NoNewline {
+						newLine = line.Content
+					}
+					hunk.newLines = append(hunk.newLines, newLine)
+				case DiffRemoved:
+					hunk.Lines = append(hunk.Lines, "-"+line.Content)
+				}
+			}
+			hunks = append(hunks, hunk)
+		}
+	}
+	return hunks
+}
+
+// applyHunks replaces the old line ranges of the given hunks in lines with
+// their new lines. The hunks must all be relative to lines.
+func applyHunks(lines []string, hunks []*AbsorbHunk) []string {
+	sorted := append([]*AbsorbHunk{}, hunks...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].oldStart < sorted[j].oldStart })
+
+	var result []string
+	next := 0 // Index of the next original line to copy.
+	for _, hunk := range sorted {
+		// A hunk that removes lines starts at oldStart; one that only adds
+		// lines inserts them after oldStart.
+		start := hunk.oldStart - 1
+		if hunk.oldCount == 0 {
+			start = hunk.oldStart
+		}
+		result = append(result, lines[next:start]...)
+		result = append(result, hunk.newLines...)
+		next = start + hunk.oldCount
+	}
+	return append(result, lines[next:]...)
+}
+
+// splitLinesKeepEnds splits s into lines that keep their trailing newline.
+func splitLinesKeepEnds(s string) []string {
+	var lines []string
+	for s != "" {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			lines = append(lines, s)
+			break
+		}
+		lines = append(lines, s[:i+1])
+		s = s[i+1:]
+	}
+	return lines
+}
+
+// containsSHA reports whether shas contains sha.
+func containsSHA(shas []string, sha string) bool {
+	for _, s := range shas {
+		if s == sha {
+			return true
+		}
+	}
+	return false
+}
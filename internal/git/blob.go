@@ -0,0 +1,40 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadBlob returns up to limit bytes of a blob, given its object name, and
+// its full size. Blobs of working tree changes that are not stored in the
+// object database yet cannot be read; see ReadWorkingFile.
+func (g *GitCommands) ReadBlob(object string, limit int64) (data []byte, size int64, err error) {
+	output, err := ExecCommand("git", "cat-file", "-s", object).Output()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read blob %s: %v", object, err)
+	}
+	if size, err = strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err != nil {
+		return nil, 0, fmt.Errorf("failed to read blob %s: %v", object, err)
+	}
+
+	cmd := ExecCommand("git", "cat-file", "blob", object)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read blob %s: %v", object, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read blob %s: %v", object, err)
+	}
+	data, err = io.ReadAll(io.LimitReader(stdout, limit))
+	if int64(len(data)) < size {
+		// Stop git instead of reading the rest of a large blob.
+		_ = cmd.Process.Kill()
+	}
+	_ = cmd.Wait()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read blob %s: %v", object, err)
+	}
+	return data, size, nil
+}
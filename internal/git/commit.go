@@ -2,18 +2,27 @@ package git
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"strings"
 )
 
 // CommitOptions specifies the options for the git commit command.
 type CommitOptions struct {
 	Message string
 	Amend   bool
+	// Only amends just the message, leaving staged changes out of the commit.
+	Only bool
+	// Fixup creates a "fixup!" commit for the given commit.
+	Fixup string
+	// Squash creates a "squash!" commit for the given commit. Message, if
+	// set, is added below the generated subject.
+	Squash string
 }
 
 // Commit records changes to the repository.
 func (g *GitCommands) Commit(options CommitOptions) (string, error) {
-	if options.Message == "" && !options.Amend {
+	if options.Message == "" && !options.Amend && options.Fixup == "" && options.Squash == "" {
 		return "", fmt.Errorf("commit message is required unless amending")
 	}
 
@@ -23,8 +32,24 @@ func (g *GitCommands) Commit(options CommitOptions) (string, error) {
 		args = append(args, "--amend")
 	}
 
+	if options.Fixup != "" {
+		args = append(args, "--fixup="+options.Fixup)
+	}
+
+	if options.Squash != "" {
+		args = append(args, "--squash="+options.Squash)
+	}
+
+	if options.Only {
+		args = append(args, "--only")
+	}
+
 	if options.Message != "" {
 		args = append(args, "-m", options.Message)
+	} else if options.Fixup == "" {
+		// Amending or squashing without a message keeps the generated one
+		// instead of waiting for an editor that gitx cannot show.
+		args = append(args, "--no-edit")
 	}
 
 	cmd := exec.Command("git", args...)
@@ -38,11 +63,22 @@ func (g *GitCommands) Commit(options CommitOptions) (string, error) {
 
 // ShowCommit shows the details of a specific commit.
 func (g *GitCommands) ShowCommit(commitHash string) (string, error) {
+	return g.ShowCommitWithOptions(commitHash, DiffOptions{Color: true})
+}
+
+// ShowCommitWithOptions shows a commit with the whitespace, context, rename
+// and algorithm settings of options applied to its diff.
+func (g *GitCommands) ShowCommitWithOptions(commitHash string, options DiffOptions) (string, error) {
 	if commitHash == "" {
 		commitHash = "HEAD"
 	}
 
-	cmd := exec.Command("git", "show", "--color=always", commitHash)
+	args := []string{"show"}
+	if options.Color {
+		args = append(args, "--color=always")
+	}
+	args = append(args, options.displayArgs()...)
+	cmd := exec.Command("git", append(args, commitHash)...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return string(output), fmt.Errorf("failed to show commit: %v", err)
@@ -50,3 +86,99 @@ func (g *GitCommands) ShowCommit(commitHash string) (string, error) {
 
 	return string(output), nil
 }
+
+// GetCommitMessage returns the full message (subject and body) of a commit.
+func (g *GitCommands) GetCommitMessage(commitHash string) (string, error) {
+	if commitHash == "" {
+		commitHash = "HEAD"
+	}
+
+	cmd := ExecCommand("git", "log", "-1", "--format=%B", commitHash)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("failed to get commit message: %v", err)
+	}
+
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// RewordCommit replaces the message of any commit on the current branch.
+// HEAD is amended directly; older commits are rewritten with a rebase.
+func (g *GitCommands) RewordCommit(commitHash, message string) (string, error) {
+	if commitHash == "" || strings.TrimSpace(message) == "" {
+		return "", fmt.Errorf("commit hash and message are required")
+	}
+
+	if err := g.checkOnCurrentBranch(commitHash); err != nil {
+		return "", err
+	}
+	if g.isHead(commitHash) {
+		return g.Commit(CommitOptions{Message: message, Amend: true, Only: true})
+	}
+
+	messageFile, err := writeTempFile("gitx-reword-", message)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.Remove(messageFile)
+	}()
+
+	base := g.CommitParent(commitHash)
+	todo, err := g.GetRebaseTodo(base)
+	if err != nil {
+		return "", err
+	}
+
+	fullHash, err := g.ResolveCommit(commitHash)
+	if err != nil {
+		return "", err
+	}
+	var rewritten []RebaseTodoLine
+	for _, line := range todo {
+		rewritten = append(rewritten, line)
+		if line.SHA == fullHash {
+			rewritten = append(rewritten, RebaseTodoLine{
+				Action:  "exec",
+				Command: "git commit --amend --only --allow-empty --quiet -F " + shellQuote(messageFile),
+			})
+		}
+	}
+
+	return g.RunRebaseTodo(base, rewritten)
+}
+
+// ResolveCommit expands a revision to its full commit hash.
+func (g *GitCommands) ResolveCommit(rev string) (string, error) {
+	output, err := ExecCommand("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown commit %s", rev)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// isHead reports whether rev points at the current HEAD commit.
+func (g *GitCommands) isHead(rev string) bool {
+	head, err := g.ResolveCommit("HEAD")
+	if err != nil {
+		return false
+	}
+	commit, err := g.ResolveCommit(rev)
+	return err == nil && commit == head
+}
+
+// checkOnCurrentBranch returns an error unless rev is in the history of HEAD.
+// Rebasing the current branch can only rewrite such commits; for any other
+// commit it would move the branch onto an unrelated base.
+func (g *GitCommands) checkOnCurrentBranch(rev string) error {
+	if err := ExecCommand("git", "merge-base", "--is-ancestor", rev, "HEAD").Run(); err != nil {
+		return fmt.Errorf("commit %s is not on the current branch", rev)
+	}
+	return nil
+}
+
+// IsCommitPushed reports whether a commit is already contained in the
+// upstream of the current branch. It is false when there is no upstream.
+func (g *GitCommands) IsCommitPushed(commitHash string) bool {
+	return ExecCommand("git", "merge-base", "--is-ancestor", commitHash, "@{upstream}").Run() == nil
+}
@@ -0,0 +1,115 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BranchComparison describes how a branch differs from the base branch it
+// is going to be merged into, like a pull request does.
+type BranchComparison struct {
+	Base      string
+	Branch    string
+	MergeBase string
+	Ahead     []string // Commits only on Branch as "sha subject", newest first.
+	Behind    []string // Commits only on Base as "sha subject", newest first.
+	Files     []FileStat
+}
+
+// FileStat is the number of lines added and deleted in a file, as listed by
+// `git diff --numstat`.
+type FileStat struct {
+	Path    string
+	OldPath string // The path before a rename.
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// DefaultBaseBranch returns the branch that branch is usually merged into:
+// the default branch of the remote it tracks, or of origin if it tracks no
+// remote, when that is known, otherwise the first local branch named main,
+// master, trunk or develop.
+func (g *GitCommands) DefaultBaseBranch(branch string) (string, error) {
+	remote := "origin"
+	if output, err := ExecCommand("git", "config", "--get", "branch."+branch+".remote").Output(); err == nil {
+		// "." is the remote of branches tracking a local branch.
+		if name := strings.TrimSpace(string(output)); name != "" && name != "." {
+			remote = name
+		}
+	}
+	output, err := ExecCommand("git", "symbolic-ref", "--quiet", "--short", "refs/remotes/"+remote+"/HEAD").Output()
+	if err == nil && strings.TrimSpace(string(output)) != "" {
+		return strings.TrimSpace(string(output)), nil
+	}
+	for _, candidate := range []string{"main", "master", "trunk", "develop"} {
+		if ExecCommand("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+candidate).Run() == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("failed to find a base branch: %s/HEAD is not set and there is no main, master, trunk or develop branch", remote)
+}
+
+// CompareWithBase compares branch with base: the commits unique to each
+// side, their merge base and the files changed on branch since then.
+func (g *GitCommands) CompareWithBase(base, branch string) (*BranchComparison, error) {
+	if base == "" || branch == "" {
+		return nil, fmt.Errorf("base and branch are required")
+	}
+
+	mergeBase, err := ExecCommand("git", "merge-base", base, branch).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge base of %s and %s: %v", base, branch, err)
+	}
+	comparison := &BranchComparison{Base: base, Branch: branch, MergeBase: strings.TrimSpace(string(mergeBase))}
+
+	if comparison.Ahead, err = g.CommitsInRange(base + ".." + branch); err != nil {
+		return nil, err
+	}
+	if comparison.Behind, err = g.CommitsInRange(branch + ".." + base); err != nil {
+		return nil, err
+	}
+	if comparison.Files, err = g.DiffNumstat(DiffOptions{Commit1: base, Commit2: branch, ThreeDot: true}); err != nil {
+		return nil, err
+	}
+	return comparison, nil
+}
+
+// DiffNumstat returns the lines added and deleted per file in the diff
+// described by options.
+func (g *GitCommands) DiffNumstat(options DiffOptions) ([]FileStat, error) {
+	args := append([]string{"diff", "--numstat", "-z"}, options.displayArgs()...)
+	if options.Cached {
+		args = append(args, "--cached")
+	}
+	args = append(args, options.revisionArgs()...)
+	if len(options.Paths) > 0 {
+		args = append(append(args, "--"), options.Paths...)
+	}
+
+	output, err := ExecCommand("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff stats: %v", err)
+	}
+
+	// Entries are "added\tdeleted\tpath" terminated by NUL. For renames the
+	// path is empty and the old and new paths follow as two more fields.
+	var stats []FileStat
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		stat := FileStat{Path: parts[2], Binary: parts[0] == "-"}
+		stat.Added, _ = strconv.Atoi(parts[0])
+		stat.Deleted, _ = strconv.Atoi(parts[1])
+		if stat.Path == "" && i+2 < len(fields) {
+			stat.OldPath, stat.Path = fields[i+1], fields[i+2]
+			i += 2
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
@@ -3,15 +3,77 @@ package git
 import (
 	"fmt"
 	"os/exec"
+	"strings"
 )
 
 // DiffOptions specifies the options for the git diff command.
 type DiffOptions struct {
 	Commit1 string
 	Commit2 string
-	Cached  bool
-	Stat    bool
-	Color   bool
+	// ThreeDot diffs Commit2 against the merge base of both commits
+	// (Commit1...Commit2) instead of against Commit1.
+	ThreeDot bool
+	Paths    []string // Limit the diff to these paths.
+	Cached   bool
+	Stat     bool
+	Color    bool
+
+	IgnoreAllSpace    bool // -w
+	IgnoreSpaceChange bool // -b
+	IgnoreBlankLines  bool
+	Context           int // Lines of context around changes; 0 keeps git's default.
+	FindRenames       int // Rename similarity threshold in percent; 0 keeps git's default.
+	Algorithm         DiffAlgorithm
+}
+
+// DiffAlgorithm is the algorithm git uses to compute a diff.
+type DiffAlgorithm string
+
+const (
+	DiffAlgorithmDefault   DiffAlgorithm = ""
+	DiffAlgorithmMyers     DiffAlgorithm = "myers"
+	DiffAlgorithmPatience  DiffAlgorithm = "patience"
+	DiffAlgorithmHistogram DiffAlgorithm = "histogram"
+)
+
+// revisionArgs returns the revisions to diff, before any "--".
+func (o DiffOptions) revisionArgs() []string {
+	switch {
+	case o.ThreeDot && o.Commit1 != "" && o.Commit2 != "":
+		return []string{o.Commit1 + "..." + o.Commit2}
+	case o.Commit1 != "" && o.Commit2 != "":
+		return []string{o.Commit1, o.Commit2}
+	case o.Commit1 != "":
+		return []string{o.Commit1}
+	case o.Commit2 != "":
+		return []string{o.Commit2}
+	}
+	return nil
+}
+
+// displayArgs returns the arguments for the options that change how a diff
+// is computed, shared by `git diff` and `git show`.
+func (o DiffOptions) displayArgs() []string {
+	var args []string
+	if o.IgnoreAllSpace {
+		args = append(args, "--ignore-all-space")
+	}
+	if o.IgnoreSpaceChange {
+		args = append(args, "--ignore-space-change")
+	}
+	if o.IgnoreBlankLines {
+		args = append(args, "--ignore-blank-lines")
+	}
+	if o.Context > 0 {
+		args = append(args, fmt.Sprintf("--unified=%d", o.Context))
+	}
+	if o.FindRenames > 0 {
+		args = append(args, fmt.Sprintf("--find-renames=%d%%", o.FindRenames))
+	}
+	if o.Algorithm != DiffAlgorithmDefault {
+		args = append(args, "--diff-algorithm="+string(o.Algorithm))
+	}
+	return args
 }
 
 // ShowDiff shows changes between commits, commit and working tree, etc.
@@ -27,16 +89,11 @@ func (g *GitCommands) ShowDiff(options DiffOptions) (string, error) {
 	if options.Stat {
 		args = append(args, "--stat")
 	}
+	args = append(args, options.displayArgs()...)
 
-	if options.Commit1 != "" || options.Commit2 != "" {
-		args = append(args, "--")
-	}
-
-	if options.Commit1 != "" {
-		args = append(args, options.Commit1)
-	}
-	if options.Commit2 != "" {
-		args = append(args, options.Commit2)
+	args = append(args, options.revisionArgs()...)
+	if len(options.Paths) > 0 {
+		args = append(append(args, "--"), options.Paths...)
 	}
 
 	cmd := exec.Command("git", args...)
@@ -47,3 +104,41 @@ func (g *GitCommands) ShowDiff(options DiffOptions) (string, error) {
 
 	return string(output), nil
 }
+
+// ChangedFile is a file listed by `git diff --name-status`.
+type ChangedFile struct {
+	Status  string // The change letter, e.g. "M", "A", "D" or "R".
+	Path    string
+	OldPath string // The path before a rename or copy.
+}
+
+// DiffFiles lists the files changed in the diff described by options.
+func (g *GitCommands) DiffFiles(options DiffOptions) ([]ChangedFile, error) {
+	args := append([]string{"diff", "--name-status", "-z"}, options.displayArgs()...)
+	if options.Cached {
+		args = append(args, "--cached")
+	}
+	args = append(args, options.revisionArgs()...)
+	if len(options.Paths) > 0 {
+		args = append(append(args, "--"), options.Paths...)
+	}
+
+	output, err := ExecCommand("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %v", err)
+	}
+
+	// Each entry is the status followed by one path, or two for renames and
+	// copies, all terminated by NUL.
+	var files []ChangedFile
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		file := ChangedFile{Status: fields[i][:1], Path: fields[i+1]}
+		if (file.Status == "R" || file.Status == "C") && i+2 < len(fields) {
+			file.OldPath, file.Path = file.Path, fields[i+2]
+			i++
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
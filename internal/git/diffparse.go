@@ -0,0 +1,183 @@
+package git
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hunkHeaderRegex matches "@@ -12,3 +12,4 @@" hunk headers.
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// DiffLineKind is the kind of a line within a diff hunk.
+type DiffLineKind int
+
+const (
+	DiffContext DiffLineKind = iota
+	DiffAdded
+	DiffRemoved
+)
+
+// DiffLine is a single line of a hunk with its line numbers in the old and
+// new file. The number of the side a line does not exist on is 0.
+type DiffLine struct {
+	Kind      DiffLineKind
+	Content   string // The line without its +, - or space prefix.
+	OldLine   int
+	NewLine   int
+	NoNewline bool // Set when the line is not terminated by a newline.
+}
+
+// DiffHunk is one "@@ -a,b +c,d @@" section of a file diff.
+type DiffHunk struct {
+	Header   string
+	OldStart int
+	OldCount int
+	NewStart int
+	NewCount int
+	Lines    []DiffLine
+}
+
+// FileDiff is the diff of a single file.
+type FileDiff struct {
+	OldPath string
+	NewPath string
+	Header  []string // Extended header lines, e.g. "new file mode 100644".
+	IsNew   bool
+	Deleted bool
+	Binary  bool
+	// OldBlob and NewBlob are the abbreviated object names from the "index"
+	// line. They are all zeros for the missing side of an added or deleted
+	// file, and NewBlob may not be stored yet for working tree changes.
+	OldBlob string
+	NewBlob string
+	Hunks   []DiffHunk
+}
+
+// Path returns the path of the file after the change, or before it for
+// deleted files.
+func (f FileDiff) Path() string {
+	if f.NewPath == "" {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// ParsedDiff is the parsed output of `git diff` or `git show`.
+type ParsedDiff struct {
+	Preamble []string // Lines before the first file, e.g. a commit header.
+	Files    []FileDiff
+	// Combined is set when the diff contains a combined diff of a merge
+	// commit, which is not parsed.
+	Combined bool
+}
+
+// ParseDiff parses unified diff output without colors. Lines it does not
+// understand before the first file are kept in the preamble.
+func ParseDiff(diff string) ParsedDiff {
+	var parsed ParsedDiff
+	var file *FileDiff
+	var hunk *DiffHunk
+	oldLine, newLine := 0, 0
+
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --cc "), strings.HasPrefix(line, "diff --combined "):
+			parsed.Combined = true
+			return parsed
+		case strings.HasPrefix(line, "diff --git "):
+			parsed.Files = append(parsed.Files, FileDiff{})
+			file = &parsed.Files[len(parsed.Files)-1]
+			hunk = nil
+			file.OldPath, file.NewPath = parseDiffGitPaths(strings.TrimPrefix(line, "diff --git "))
+		case file == nil:
+			parsed.Preamble = append(parsed.Preamble, line)
+		case strings.HasPrefix(line, "@@"):
+			match := hunkHeaderRegex.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			file.Hunks = append(file.Hunks, DiffHunk{
+				Header:   line,
+				OldStart: atoiDefault(match[1], 0),
+				OldCount: atoiDefault(match[2], 1),
+				NewStart: atoiDefault(match[3], 0),
+				NewCount: atoiDefault(match[4], 1),
+			})
+			hunk = &file.Hunks[len(file.Hunks)-1]
+			oldLine, newLine = hunk.OldStart, hunk.NewStart
+		case hunk == nil:
+			parseFileHeaderLine(file, line)
+		case strings.HasPrefix(line, "+"):
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffAdded, Content: line[1:], NewLine: newLine})
+			newLine++
+		case strings.HasPrefix(line, "-"):
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffRemoved, Content: line[1:], OldLine: oldLine})
+			oldLine++
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" applies to the previous line.
+			if len(hunk.Lines) > 0 {
+				hunk.Lines[len(hunk.Lines)-1].NoNewline = true
+			}
+		default:
+			// Context lines start with a space, which editors sometimes strip
+			// from empty lines.
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffContext, Content: strings.TrimPrefix(line, " "), OldLine: oldLine, NewLine: newLine})
+			oldLine++
+			newLine++
+		}
+	}
+	return parsed
+}
+
+// parseFileHeaderLine records an extended header line of a file diff.
+func parseFileHeaderLine(file *FileDiff, line string) {
+	switch {
+	case strings.HasPrefix(line, "--- "):
+		if path := strings.TrimPrefix(line, "--- "); path == "/dev/null" {
+			file.IsNew = true
+		} else {
+			file.OldPath = strings.TrimPrefix(path, "a/")
+		}
+		return
+	case strings.HasPrefix(line, "+++ "):
+		if path := strings.TrimPrefix(line, "+++ "); path == "/dev/null" {
+			file.Deleted = true
+		} else {
+			file.NewPath = strings.TrimPrefix(path, "b/")
+		}
+		return
+	case strings.HasPrefix(line, "new file mode"):
+		file.IsNew = true
+	case strings.HasPrefix(line, "deleted file mode"):
+		file.Deleted = true
+	case strings.HasPrefix(line, "Binary files"), strings.HasPrefix(line, "GIT binary patch"):
+		file.Binary = true
+	case strings.HasPrefix(line, "rename from "):
+		file.OldPath = strings.TrimPrefix(line, "rename from ")
+	case strings.HasPrefix(line, "rename to "):
+		file.NewPath = strings.TrimPrefix(line, "rename to ")
+	case strings.HasPrefix(line, "index "):
+		blobs, _, _ := strings.Cut(strings.TrimPrefix(line, "index "), " ")
+		file.OldBlob, file.NewBlob, _ = strings.Cut(blobs, "..")
+	}
+	file.Header = append(file.Header, line)
+}
+
+// parseDiffGitPaths splits the "a/old b/new" part of a "diff --git" line.
+// Paths with spaces are ambiguous here; the ---/+++ lines refine them.
+func parseDiffGitPaths(paths string) (oldPath, newPath string) {
+	if i := strings.Index(paths, " b/"); i >= 0 && strings.HasPrefix(paths, "a/") {
+		return paths[2:i], paths[i+3:]
+	}
+	return paths, paths
+}
+
+// atoiDefault parses s as an integer, returning def when s is empty or invalid.
+func atoiDefault(s string, def int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return n
+}
@@ -158,6 +158,154 @@ func TestGitCommands_Diff(t *testing.T) {
 	if !strings.Contains(diff, "+modified") {
 		t.Errorf("expected diff to show added line, got: %s", diff)
 	}
+
+	// Whitespace-only changes disappear with -w.
+	if err := os.WriteFile("diff-test.txt", []byte("ini  tial"), 0644); err != nil {
+		t.Fatalf("failed to modify test file: %v", err)
+	}
+	diff, err = g.ShowDiff(DiffOptions{IgnoreAllSpace: true})
+	if err != nil || strings.Contains(diff, "@@") {
+		t.Errorf("ShowDiff() with IgnoreAllSpace = %q, %v; want no hunks", diff, err)
+	}
+	diff, err = g.ShowDiff(DiffOptions{IgnoreSpaceChange: true, Context: 1, Algorithm: DiffAlgorithmHistogram})
+	if err != nil || !strings.Contains(diff, "+ini  tial") {
+		t.Errorf("ShowDiff() with IgnoreSpaceChange = %q, %v; want the added space", diff, err)
+	}
+
+	// A strict rename threshold turns a modified rename into delete and add.
+	if err := os.WriteFile("diff-test.txt", []byte("initial"), 0644); err != nil {
+		t.Fatalf("failed to restore test file: %v", err)
+	}
+	if err := ExecCommand("git", "mv", "diff-test.txt", "renamed.txt").Run(); err != nil {
+		t.Fatalf("failed to rename file: %v", err)
+	}
+	show, err := g.ShowDiff(DiffOptions{Cached: true, FindRenames: 50})
+	if err != nil || !strings.Contains(show, "rename to renamed.txt") {
+		t.Errorf("ShowDiff() with FindRenames = %q, %v; want a rename", show, err)
+	}
+}
+
+func TestGitCommands_DiffBetweenCommits(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "base.txt", "base", "Base commit")
+	if err := ExecCommand("git", "checkout", "-b", "feature").Run(); err != nil {
+		t.Fatalf("failed to create branch: %v", err)
+	}
+	createAndCommitFile(t, g, "feature.txt", "feature", "Feature commit")
+	if err := ExecCommand("git", "checkout", "master").Run(); err != nil {
+		t.Fatalf("failed to checkout master: %v", err)
+	}
+	createAndCommitFile(t, g, "master.txt", "master", "Master commit")
+	if err := ExecCommand("git", "mv", "base.txt", "moved.txt").Run(); err != nil {
+		t.Fatalf("failed to rename file: %v", err)
+	}
+	if _, err := g.Commit(CommitOptions{Message: "Move base"}); err != nil {
+		t.Fatalf("failed to commit rename: %v", err)
+	}
+
+	// Revisions must not be taken for paths.
+	diff, err := g.ShowDiff(DiffOptions{Commit1: "master", Commit2: "feature"})
+	if err != nil || !strings.Contains(diff, "+++ b/feature.txt") || !strings.Contains(diff, "--- a/master.txt") {
+		t.Errorf("two-dot ShowDiff() = %q, %v; want both sides' changes", diff, err)
+	}
+
+	files, err := g.DiffFiles(DiffOptions{Commit1: "master", Commit2: "feature", ThreeDot: true})
+	if err != nil {
+		t.Fatalf("DiffFiles() failed: %v", err)
+	}
+	if len(files) != 1 || files[0] != (ChangedFile{Status: "A", Path: "feature.txt"}) {
+		t.Errorf("three-dot DiffFiles() = %+v; want only feature.txt", files)
+	}
+
+	files, err = g.DiffFiles(DiffOptions{Commit1: "master~2", Commit2: "master"})
+	if err != nil {
+		t.Fatalf("DiffFiles() failed: %v", err)
+	}
+	want := []ChangedFile{{Status: "A", Path: "master.txt"}, {Status: "R", Path: "moved.txt", OldPath: "base.txt"}}
+	if len(files) != len(want) || files[0] != want[0] || files[1] != want[1] {
+		t.Errorf("DiffFiles() = %+v; want %+v", files, want)
+	}
+
+	diff, err = g.ShowDiff(DiffOptions{Commit1: "master~2", Commit2: "master", Paths: []string{"master.txt"}})
+	if err != nil || !strings.Contains(diff, "master.txt") || strings.Contains(diff, "moved.txt") {
+		t.Errorf("ShowDiff() with Paths = %q, %v; want only master.txt", diff, err)
+	}
+	diff, err = g.ShowDiff(DiffOptions{Commit1: "master~2", Commit2: "master", Paths: []string{"moved.txt", "base.txt"}})
+	if err != nil || !strings.Contains(diff, "rename from base.txt") {
+		t.Errorf("ShowDiff() with both paths of a rename = %q, %v; want the rename", diff, err)
+	}
+}
+
+func TestGitCommands_CompareWithBase(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "base.txt", "one\ntwo\n", "Base commit")
+	base, err := g.DefaultBaseBranch("master")
+	if err != nil || base != "master" {
+		t.Fatalf("DefaultBaseBranch() = %q, %v; want master", base, err)
+	}
+	mergeBase, _ := ExecCommand("git", "rev-parse", "HEAD").Output()
+
+	if err := ExecCommand("git", "checkout", "-b", "feature").Run(); err != nil {
+		t.Fatalf("failed to create branch: %v", err)
+	}
+	createAndCommitFile(t, g, "base.txt", "one\n2\nthree\n", "Change base")
+	createAndCommitFile(t, g, "image.bin", "\x00\x01", "Add binary")
+	if err := ExecCommand("git", "checkout", "master").Run(); err != nil {
+		t.Fatalf("failed to checkout master: %v", err)
+	}
+	createAndCommitFile(t, g, "other.txt", "other", "Master commit")
+
+	comparison, err := g.CompareWithBase("master", "feature")
+	if err != nil {
+		t.Fatalf("CompareWithBase() failed: %v", err)
+	}
+	if comparison.MergeBase != strings.TrimSpace(string(mergeBase)) {
+		t.Errorf("MergeBase = %q, want %q", comparison.MergeBase, strings.TrimSpace(string(mergeBase)))
+	}
+	if len(comparison.Ahead) != 2 || !strings.HasSuffix(comparison.Ahead[0], "Add binary") {
+		t.Errorf("Ahead = %v, want the two feature commits", comparison.Ahead)
+	}
+	if len(comparison.Behind) != 1 || !strings.HasSuffix(comparison.Behind[0], "Master commit") {
+		t.Errorf("Behind = %v, want the master commit", comparison.Behind)
+	}
+	want := []FileStat{{Path: "base.txt", Added: 2, Deleted: 1}, {Path: "image.bin", Binary: true}}
+	if len(comparison.Files) != 2 || comparison.Files[0] != want[0] || comparison.Files[1] != want[1] {
+		t.Errorf("Files = %+v, want %+v", comparison.Files, want)
+	}
+
+	// The default branch of origin wins over local guesses.
+	if err := ExecCommand("git", "update-ref", "refs/remotes/origin/trunk", "HEAD").Run(); err != nil {
+		t.Fatalf("failed to create remote branch: %v", err)
+	}
+	if err := ExecCommand("git", "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/trunk").Run(); err != nil {
+		t.Fatalf("failed to set origin/HEAD: %v", err)
+	}
+	if base, err := g.DefaultBaseBranch("feature"); err != nil || base != "origin/trunk" {
+		t.Errorf("DefaultBaseBranch() = %q, %v; want origin/trunk", base, err)
+	}
+
+	// A branch tracking another remote is compared with that remote's default.
+	if err := ExecCommand("git", "update-ref", "refs/remotes/upstream/develop", "HEAD").Run(); err != nil {
+		t.Fatalf("failed to create remote branch: %v", err)
+	}
+	if err := ExecCommand("git", "symbolic-ref", "refs/remotes/upstream/HEAD", "refs/remotes/upstream/develop").Run(); err != nil {
+		t.Fatalf("failed to set upstream/HEAD: %v", err)
+	}
+	if err := ExecCommand("git", "config", "branch.feature.remote", "upstream").Run(); err != nil {
+		t.Fatalf("failed to set the remote of feature: %v", err)
+	}
+	if base, err := g.DefaultBaseBranch("feature"); err != nil || base != "upstream/develop" {
+		t.Errorf("DefaultBaseBranch() = %q, %v; want upstream/develop", base, err)
+	}
+	if base, err := g.DefaultBaseBranch("master"); err != nil || base != "origin/trunk" {
+		t.Errorf("DefaultBaseBranch() of a branch without upstream = %q, %v; want origin/trunk", base, err)
+	}
 }
 
 func TestGitCommands_Commit(t *testing.T) {
@@ -241,6 +389,16 @@ func TestGitCommands_Merge(t *testing.T) {
 	}
 	createAndCommitFile(t, g, "master2.txt", "master2 content", "master2 commit")
 
+	incoming, err := g.CommitsInRange("HEAD.." + branchName)
+	if err != nil || len(incoming) != 1 || !strings.HasSuffix(incoming[0], " feature commit") {
+		t.Errorf("CommitsInRange() = %v, %v; want the feature commit", incoming, err)
+	}
+
+	// The branches diverged, so a fast-forward is impossible.
+	if _, err := g.Merge(MergeOptions{BranchName: branchName, FastForwardOnly: true}); err == nil {
+		t.Error("Merge() with FastForwardOnly should fail for diverged branches")
+	}
+
 	// Merge feature branch into master
 	output, err := g.Merge(MergeOptions{BranchName: branchName})
 	if err != nil {
@@ -325,6 +483,23 @@ func TestGitCommands_FileOperations(t *testing.T) {
 	if _, err := os.Stat("destination.txt"); os.IsNotExist(err) {
 		t.Error("destination file does not exist after move")
 	}
+
+	// Test Clean
+	if err := os.MkdirAll(filepath.Join("scratch", "deep"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join("scratch", "deep", "tmp.txt"), []byte("tmp"), 0644); err != nil {
+		t.Fatalf("failed to create untracked file: %v", err)
+	}
+	if _, err := g.CleanFiles([]string{"scratch/"}); err != nil {
+		t.Errorf("CleanFiles() failed: %v", err)
+	}
+	if _, err := os.Stat("scratch"); !os.IsNotExist(err) {
+		t.Error("untracked directory should have been deleted")
+	}
+	if _, err := os.Stat("new-file.txt"); err != nil {
+		t.Error("CleanFiles() should only delete the given paths")
+	}
 }
 
 func TestGitCommands_Stash(t *testing.T) {
@@ -343,9 +518,807 @@ func TestGitCommands_Stash(t *testing.T) {
 	if _, err := g.Stash(StashOptions{Push: true, Message: "test stash"}); err != nil {
 		t.Fatalf("Stash() push failed: %v", err)
 	}
+	stashes, err := g.GetStashes()
+	sha, _ := g.ResolveCommit("stash@{0}")
+	if err != nil || len(stashes) != 1 || *stashes[0] != (Stash{Name: "stash@{0}", SHA: sha, Branch: "On master", Message: "test stash"}) {
+		t.Errorf("GetStashes() = %v, %v; want the pushed stash", stashes, err)
+	}
 
 	// Stash apply
 	if _, err := g.Stash(StashOptions{Apply: true}); err != nil {
 		t.Errorf("Stash() apply failed: %v", err)
 	}
 }
+
+func TestGitCommands_GetConfigValue(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	value, err := g.GetConfigValue("gitx.test.unset")
+	if err != nil || value != "" {
+		t.Errorf("GetConfigValue() on unset key = %q, %v; want empty, nil", value, err)
+	}
+
+	if err := ExecCommand("git", "config", "gitx.test.value", "42").Run(); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+	value, err = g.GetConfigValue("gitx.test.value")
+	if err != nil || value != "42" {
+		t.Errorf("GetConfigValue() = %q, %v; want 42, nil", value, err)
+	}
+}
+
+func TestGitCommands_ReadWorkingFile(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	if err := os.MkdirAll("sub", 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join("sub", "notes.txt"), []byte("hello world\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	// Paths are relative to the repository root, not the current directory.
+	if err := os.Chdir("sub"); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+
+	g := NewGitCommands()
+	content, truncated, err := g.ReadWorkingFile("sub/notes.txt", 5)
+	if err != nil || content != "hello" || !truncated {
+		t.Errorf("ReadWorkingFile() = %q, %v, %v; want hello, true, nil", content, truncated, err)
+	}
+	content, truncated, err = g.ReadWorkingFile("sub/notes.txt", 1024)
+	if err != nil || content != "hello world\n" || truncated {
+		t.Errorf("ReadWorkingFile() = %q, %v, %v; want the whole file", content, truncated, err)
+	}
+	if _, _, err := g.ReadWorkingFile("missing.txt", 1024); err == nil {
+		t.Error("ReadWorkingFile() on a missing file should fail")
+	}
+}
+
+func TestGitCommands_ReadBlob(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "data.bin", "\x00\x01binary content", "Add data")
+	object, err := ExecCommand("git", "rev-parse", "HEAD:data.bin").Output()
+	if err != nil {
+		t.Fatalf("failed to find blob: %v", err)
+	}
+
+	data, size, err := g.ReadBlob(strings.TrimSpace(string(object)), 4)
+	if err != nil {
+		t.Fatalf("ReadBlob() failed: %v", err)
+	}
+	if string(data) != "\x00\x01bi" || size != 16 {
+		t.Errorf("ReadBlob() = %q, %d; want the first 4 of 16 bytes", data, size)
+	}
+	if _, _, err := g.ReadBlob("0123456789abcdef", 4); err == nil {
+		t.Error("ReadBlob() of a missing object should fail")
+	}
+}
+
+func TestGitCommands_Untracked(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "tracked.txt", "tracked", "Initial commit")
+	if err := os.MkdirAll(filepath.Join("assets", "icons"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	files := map[string]string{
+		"new.txt":                                 "one\ntwo\n",
+		filepath.Join("assets", "logo.png"):       "\x89PNG\x00\x00",
+		filepath.Join("assets", "icons", "a.svg"): "<svg/>",
+		".gitignore":                              "*.log\n",
+		filepath.Join("assets", "debug.log"):      "ignored",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	if err := os.Symlink("tracked.txt", "link"); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	diff, err := g.DiffUntracked("new.txt", DiffOptions{})
+	if err != nil {
+		t.Fatalf("DiffUntracked() failed: %v", err)
+	}
+	if parsed := ParseDiff(diff); len(parsed.Files) != 1 || !parsed.Files[0].IsNew || len(parsed.Files[0].Hunks[0].Lines) != 2 {
+		t.Errorf("DiffUntracked() = %q; want a new file adding two lines", diff)
+	}
+	diff, err = g.DiffUntracked("assets/logo.png", DiffOptions{})
+	if err != nil || !ParseDiff(diff).Files[0].Binary {
+		t.Errorf("DiffUntracked() on a binary file = %q, %v", diff, err)
+	}
+
+	link, err := g.StatWorkingFile("link")
+	if err != nil || !link.Symlink || link.Target != "tracked.txt" {
+		t.Errorf("StatWorkingFile() on a symlink = %+v, %v", link, err)
+	}
+
+	listed, err := g.ListUntracked("assets/")
+	if err != nil {
+		t.Fatalf("ListUntracked() failed: %v", err)
+	}
+	want := []WorkingFile{{Path: "assets/icons/a.svg", Size: 6}, {Path: "assets/logo.png", Size: 6}}
+	if len(listed) != len(want) || listed[0] != want[0] || listed[1] != want[1] {
+		t.Errorf("ListUntracked() = %+v, want %+v", listed, want)
+	}
+}
+
+func TestGitCommands_Trailers(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	mailmap := "Mapped Name <mapped@example.com> <test@example.com>\nOnly Mailmap <only@example.com>\n"
+	if err := os.WriteFile(".mailmap", []byte(mailmap), 0644); err != nil {
+		t.Fatalf("failed to write .mailmap: %v", err)
+	}
+
+	authors, err := g.GetAuthors()
+	if err != nil {
+		t.Fatalf("GetAuthors() failed: %v", err)
+	}
+	want := []string{"Mapped Name <mapped@example.com>", "Only Mailmap <only@example.com>"}
+	if strings.Join(authors, "|") != strings.Join(want, "|") {
+		t.Errorf("GetAuthors() = %q, want %q", authors, want)
+	}
+
+	message, err := g.InterpretTrailers("Title\n\nBody", []string{"Co-authored-by: " + authors[1]})
+	if err != nil {
+		t.Fatalf("InterpretTrailers() failed: %v", err)
+	}
+	if message != "Title\n\nBody\n\nCo-authored-by: Only Mailmap <only@example.com>\n" {
+		t.Errorf("unexpected message with trailer: %q", message)
+	}
+
+	message, err = g.InterpretTrailers(message, []string{"Co-authored-by: " + authors[1], "Fixes: #12"})
+	if err != nil {
+		t.Fatalf("InterpretTrailers() failed: %v", err)
+	}
+	if strings.Count(message, "Co-authored-by") != 1 || !strings.HasSuffix(message, "Fixes: #12\n") {
+		t.Errorf("trailers were duplicated or not appended: %q", message)
+	}
+}
+
+func TestGitCommands_AmendAndReword(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "first.txt", "first", "First commit\n\nWith a body")
+	createAndCommitFile(t, g, "second.txt", "second", "Second commit")
+
+	// Amending without a message keeps the existing one.
+	if err := os.WriteFile("second.txt", []byte("amended"), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+	if _, err := g.AddFiles([]string{"second.txt"}); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	if _, err := g.Commit(CommitOptions{Amend: true}); err != nil {
+		t.Fatalf("Commit() amend without message failed: %v", err)
+	}
+	if msg, _ := g.GetCommitMessage("HEAD"); msg != "Second commit" {
+		t.Errorf("amend without message changed the message to %q", msg)
+	}
+
+	// Reword HEAD.
+	if _, err := g.RewordCommit("HEAD", "Second commit, reworded"); err != nil {
+		t.Fatalf("RewordCommit() on HEAD failed: %v", err)
+	}
+	if msg, _ := g.GetCommitMessage("HEAD"); msg != "Second commit, reworded" {
+		t.Errorf("HEAD message = %q after reword", msg)
+	}
+
+	// Reword an older commit with local changes present.
+	if err := os.WriteFile("first.txt", []byte("dirty"), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+	if _, err := g.RewordCommit("HEAD~1", "First commit, reworded\n\nNew body"); err != nil {
+		t.Fatalf("RewordCommit() on older commit failed: %v", err)
+	}
+	if msg, _ := g.GetCommitMessage("HEAD~1"); msg != "First commit, reworded\n\nNew body" {
+		t.Errorf("HEAD~1 message = %q after reword", msg)
+	}
+	if msg, _ := g.GetCommitMessage("HEAD"); msg != "Second commit, reworded" {
+		t.Errorf("HEAD message = %q, rewording an older commit should keep it", msg)
+	}
+	if content, _ := os.ReadFile("first.txt"); string(content) != "dirty" {
+		t.Errorf("local changes were not restored after reword, got %q", content)
+	}
+
+	// A commit of another branch can't be reworded by rebasing this one.
+	_ = ExecCommand("git", "stash", "-q").Run()
+	_ = ExecCommand("git", "checkout", "-q", "-b", "side", "HEAD~1").Run()
+	createAndCommitFile(t, g, "side.txt", "side", "Side commit")
+	_ = ExecCommand("git", "checkout", "-q", "-").Run()
+	head, _ := g.ResolveCommit("HEAD")
+	if _, err := g.RewordCommit("side", "Reworded side"); err == nil || !strings.Contains(err.Error(), "not on the current branch") {
+		t.Errorf("RewordCommit() of another branch's commit = %v, want an error", err)
+	}
+	if after, _ := g.ResolveCommit("HEAD"); after != head {
+		t.Error("rewording another branch's commit should leave the current branch alone")
+	}
+}
+
+func TestGitCommands_FixupAndAutosquash(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "target.txt", "target", "Target commit")
+	createAndCommitFile(t, g, "other.txt", "other", "Other commit")
+
+	if g.IsCommitPushed("HEAD") {
+		t.Error("IsCommitPushed() should be false without an upstream")
+	}
+
+	if err := os.WriteFile("target.txt", []byte("fixed target"), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+	if _, err := g.AddFiles([]string{"target.txt"}); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	if _, err := g.Commit(CommitOptions{Fixup: "HEAD~1"}); err != nil {
+		t.Fatalf("Commit() fixup failed: %v", err)
+	}
+	if msg, _ := g.GetCommitMessage("HEAD"); msg != "fixup! Target commit" {
+		t.Errorf("unexpected fixup message %q", msg)
+	}
+
+	if err := os.WriteFile("other.txt", []byte("squashed other"), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+	if _, err := g.AddFiles([]string{"other.txt"}); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	if _, err := g.Commit(CommitOptions{Squash: "HEAD~1", Message: "More details"}); err != nil {
+		t.Fatalf("Commit() squash failed: %v", err)
+	}
+
+	if _, err := g.Autosquash("HEAD~3"); err != nil {
+		t.Fatalf("Autosquash() failed: %v", err)
+	}
+
+	log, err := g.ShowLog(LogOptions{Format: "%s"})
+	if err != nil {
+		t.Fatalf("ShowLog() failed: %v", err)
+	}
+	if log != "Other commit\nTarget commit\nInitial commit" {
+		t.Errorf("unexpected history after autosquash:\n%s", log)
+	}
+	if msg, _ := g.GetCommitMessage("HEAD"); msg != "Other commit\n\nMore details" {
+		t.Errorf("squash message was not combined, got %q", msg)
+	}
+	if content, _ := ExecCommand("git", "show", "HEAD~1:target.txt").Output(); string(content) != "fixed target" {
+		t.Errorf("fixup was not folded into its target, got %q", content)
+	}
+
+	// Autosquashing from another branch's commit would rebase onto it.
+	_ = ExecCommand("git", "checkout", "-q", "-b", "side", "HEAD~1").Run()
+	createAndCommitFile(t, g, "side.txt", "side", "Side commit")
+	_ = ExecCommand("git", "checkout", "-q", "-").Run()
+	head, _ := g.ResolveCommit("HEAD")
+	if _, err := g.Autosquash("side"); err == nil || !strings.Contains(err.Error(), "not on the current branch") {
+		t.Errorf("Autosquash() from another branch's commit = %v, want an error", err)
+	}
+	if after, _ := g.ResolveCommit("HEAD"); after != head {
+		t.Error("autosquashing from another branch's commit should leave the current branch alone")
+	}
+}
+
+func TestGitCommands_Absorb(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "a.txt", "one\ntwo\nthree\nfour\nfive\n", "Add a")
+	createAndCommitFile(t, g, "b.txt", "bee\n", "Add b")
+	createAndCommitFile(t, g, "a.txt", "one\ntwo\nthree\nfour\nFIVE\n", "Shout five")
+
+	files := map[string]string{
+		"a.txt": "ONE\ntwo\nthree\n4\n5\n",
+		"b.txt": "BEE\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to modify file: %v", err)
+		}
+	}
+	if _, err := g.AddFiles([]string{"a.txt", "b.txt"}); err != nil {
+		t.Fatalf("failed to add files: %v", err)
+	}
+
+	plan, err := g.PlanAbsorb()
+	if err != nil {
+		t.Fatalf("PlanAbsorb() failed: %v", err)
+	}
+	if len(plan.Hunks) != 3 {
+		t.Fatalf("expected 3 hunks, got %d", len(plan.Hunks))
+	}
+	subjects := make(map[string]string)
+	for _, hunk := range plan.Hunks {
+		subjects[hunk.Path+hunk.Header] = plan.Commits[hunk.Target].Subject
+		if strings.HasPrefix(hunk.Header, "@@ -4,2") {
+			if !hunk.Ambiguous || len(hunk.Candidates) != 2 {
+				t.Errorf("hunk touching two commits should be ambiguous, got %+v", hunk)
+			}
+			// Leave the ambiguous hunk staged.
+			hunk.Target = ""
+		} else if hunk.Ambiguous {
+			t.Errorf("hunk %s %s should not be ambiguous", hunk.Path, hunk.Header)
+		}
+	}
+	if subjects["a.txt@@ -1 +1 @@"] != "Add a" || subjects["b.txt@@ -1 +1 @@"] != "Add b" {
+		t.Errorf("unexpected targets: %v", subjects)
+	}
+
+	if _, err := g.ApplyAbsorb(plan); err != nil {
+		t.Fatalf("ApplyAbsorb() failed: %v", err)
+	}
+
+	log, err := g.ShowLog(LogOptions{Format: "%s"})
+	if err != nil {
+		t.Fatalf("ShowLog() failed: %v", err)
+	}
+	if log != "Shout five\nAdd b\nAdd a\nInitial commit" {
+		t.Errorf("unexpected history after absorb:\n%s", log)
+	}
+	if content, _ := ExecCommand("git", "show", "HEAD~2:a.txt").Output(); string(content) != "ONE\ntwo\nthree\nfour\nfive\n" {
+		t.Errorf("hunk was not absorbed into its commit, got %q", content)
+	}
+	if content, _ := ExecCommand("git", "show", "HEAD~1:b.txt").Output(); string(content) != "BEE\n" {
+		t.Errorf("hunk was not absorbed into its commit, got %q", content)
+	}
+	if content, _ := os.ReadFile("a.txt"); string(content) != files["a.txt"] {
+		t.Errorf("skipped hunk was lost from the working tree, got %q", content)
+	}
+}
+
+func TestGitCommands_AbsorbRollback(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "a.txt", "one\ntwo\n", "Add a")
+	createAndCommitFile(t, g, "a.txt", "one\nTWO\n", "Shout two")
+	if err := os.WriteFile("a.txt", []byte("one\n2\n"), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+	if _, err := g.AddFiles([]string{"a.txt"}); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+
+	plan, err := g.PlanAbsorb()
+	if err != nil || len(plan.Hunks) != 1 {
+		t.Fatalf("PlanAbsorb() = %+v, %v; want one hunk", plan, err)
+	}
+	// Folding the change into the older commit conflicts with the newer one.
+	for sha, commit := range plan.Commits {
+		if commit.Subject == "Add a" {
+			plan.Hunks[0].Target = sha
+		}
+	}
+	head, _ := g.ResolveCommit("HEAD")
+	staged, _ := g.ShowDiff(DiffOptions{Cached: true})
+
+	if _, err := g.ApplyAbsorb(plan); err == nil {
+		t.Fatal("ApplyAbsorb() into a conflicting commit should fail")
+	}
+	if after, _ := g.ResolveCommit("HEAD"); after != head {
+		t.Error("a failed absorb should leave HEAD where it was")
+	}
+	if after, _ := g.ShowDiff(DiffOptions{Cached: true}); after != staged {
+		t.Errorf("a failed absorb should keep the changes staged, got %q", after)
+	}
+}
+
+func TestGitCommands_RewriteCommits(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "a.txt", "a", "Add a")
+	createAndCommitFile(t, g, "b.txt", "b", "Add b")
+	createAndCommitFile(t, g, "c.txt", "c", "Add c")
+
+	assertLog := func(step, want string) {
+		t.Helper()
+		log, err := g.ShowLog(LogOptions{Format: "%s"})
+		if err != nil {
+			t.Fatalf("ShowLog() failed: %v", err)
+		}
+		if log != want {
+			t.Errorf("unexpected history after %s:\n%s", step, log)
+		}
+	}
+
+	if _, err := g.MoveCommitDown("HEAD"); err != nil {
+		t.Fatalf("MoveCommitDown() failed: %v", err)
+	}
+	assertLog("moving down", "Add b\nAdd c\nAdd a\nInitial commit")
+
+	if _, err := g.MoveCommitUp("HEAD~1"); err != nil {
+		t.Fatalf("MoveCommitUp() failed: %v", err)
+	}
+	assertLog("moving up", "Add c\nAdd b\nAdd a\nInitial commit")
+
+	if _, err := g.MoveCommitUp("HEAD"); err == nil {
+		t.Error("MoveCommitUp() should fail for the newest commit")
+	}
+
+	if _, err := g.SquashCommit("HEAD~1"); err != nil {
+		t.Fatalf("SquashCommit() failed: %v", err)
+	}
+	assertLog("squashing", "Add c\nAdd a\nInitial commit")
+	if msg, _ := g.GetCommitMessage("HEAD~1"); msg != "Add a\n\nAdd b" {
+		t.Errorf("squashed messages were not combined, got %q", msg)
+	}
+
+	if _, err := g.DropCommit("HEAD"); err != nil {
+		t.Fatalf("DropCommit() failed: %v", err)
+	}
+	assertLog("dropping", "Add a\nInitial commit")
+	if _, err := os.Stat("c.txt"); !os.IsNotExist(err) {
+		t.Error("dropped commit's file should be gone")
+	}
+
+	// Commits of another branch are refused instead of rebasing this branch
+	// onto their parent.
+	_ = ExecCommand("git", "checkout", "-q", "-b", "side", "HEAD~1").Run()
+	createAndCommitFile(t, g, "side.txt", "side", "Side commit")
+	_ = ExecCommand("git", "checkout", "-q", "-").Run()
+	rewrites := map[string]func(string) (string, error){
+		"DropCommit": g.DropCommit, "SquashCommit": g.SquashCommit, "MoveCommitUp": g.MoveCommitUp,
+		"MoveCommitDown": g.MoveCommitDown, "SplitCommit": g.SplitCommit,
+	}
+	for name, rewrite := range rewrites {
+		if _, err := rewrite("side"); err == nil || !strings.Contains(err.Error(), "not on the current branch") {
+			t.Errorf("%s() of another branch's commit = %v, want an error", name, err)
+		}
+	}
+	assertLog("rewriting another branch's commit", "Add a\nInitial commit")
+
+	if _, err := g.SplitCommit("HEAD"); err != nil {
+		t.Fatalf("SplitCommit() failed: %v", err)
+	}
+	status, _ := ExecCommand("git", "status", "--porcelain").Output()
+	if !strings.Contains(string(status), "?? b.txt") || !strings.Contains(string(status), "?? a.txt") {
+		t.Errorf("split commit's changes should be unstaged, got %q", status)
+	}
+	_ = ExecCommand("git", "rebase", "--abort").Run()
+}
+
+func TestGitCommands_ResetToCommit(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "a.txt", "a", "Add a")
+	createAndCommitFile(t, g, "b.txt", "b", "Add b")
+	if err := os.WriteFile("a.txt", []byte("local"), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+
+	preview, err := g.PreviewReset("HEAD~2")
+	if err != nil {
+		t.Fatalf("PreviewReset() failed: %v", err)
+	}
+	if len(preview.Commits) != 2 || !strings.HasSuffix(preview.Commits[0], "Add b") {
+		t.Errorf("unexpected commits in preview: %v", preview.Commits)
+	}
+	if len(preview.Unstaged) != 1 || preview.Unstaged[0] != "M\ta.txt" {
+		t.Errorf("unexpected unstaged changes in preview: %v", preview.Unstaged)
+	}
+	if len(preview.Changed) != 2 {
+		t.Errorf("unexpected changed paths in preview: %v", preview.Changed)
+	}
+
+	// a.txt has local changes and differs in HEAD~2, so keep must refuse.
+	if _, err := g.ResetToCommit("HEAD~2", ResetKeep); err == nil {
+		t.Error("ResetToCommit() with keep should refuse to lose local changes")
+	}
+
+	if _, err := g.ResetToCommit("HEAD~1", ResetSoft); err != nil {
+		t.Fatalf("ResetToCommit() soft failed: %v", err)
+	}
+	status, _ := ExecCommand("git", "status", "--porcelain").Output()
+	if !strings.Contains(string(status), "A  b.txt") {
+		t.Errorf("soft reset should stage the commit's changes, got %q", status)
+	}
+
+	if _, err := g.ResetToCommit("HEAD", ResetHard); err != nil {
+		t.Fatalf("ResetToCommit() hard failed: %v", err)
+	}
+	if content, _ := os.ReadFile("a.txt"); string(content) != "a" {
+		t.Errorf("hard reset should discard local changes, got %q", content)
+	}
+}
+
+func TestGitCommands_Revert(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "a.txt", "a", "Add a")
+	createAndCommitFile(t, g, "b.txt", "b", "Add b")
+
+	// Staged changes would be committed along with a squashed revert.
+	if err := os.WriteFile("staged.txt", []byte("staged"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := g.AddFiles([]string{"staged.txt"}); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	if _, err := g.Revert(RevertOptions{Commits: []string{"HEAD", "HEAD~1"}, Squash: true}); err == nil || !strings.Contains(err.Error(), "staged") {
+		t.Errorf("Revert() squashed with staged changes = %v, want an error", err)
+	}
+	if msg, _ := g.GetCommitMessage("HEAD"); msg != "Add b" {
+		t.Errorf("squashed revert with staged changes should not commit, HEAD is %q", msg)
+	}
+	if _, err := g.ResetFiles([]string{"staged.txt"}); err != nil {
+		t.Fatalf("failed to unstage file: %v", err)
+	}
+
+	if _, err := g.Revert(RevertOptions{Commits: []string{"HEAD", "HEAD~1"}, Squash: true}); err != nil {
+		t.Fatalf("Revert() squashed failed: %v", err)
+	}
+	if msg, _ := g.GetCommitMessage("HEAD"); !strings.HasPrefix(msg, "Revert 2 commits") {
+		t.Errorf("unexpected squashed revert message %q", msg)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed by the revert", name)
+		}
+	}
+
+	// Create a merge commit and revert it against its first parent.
+	_ = ExecCommand("git", "checkout", "-q", "-b", "feature").Run()
+	createAndCommitFile(t, g, "feature.txt", "feature", "Add feature")
+	_ = ExecCommand("git", "checkout", "-q", "-").Run()
+	if output, err := ExecCommand("git", "merge", "--no-ff", "--no-edit", "feature").CombinedOutput(); err != nil {
+		t.Fatalf("failed to merge: %v: %s", err, output)
+	}
+
+	parents, err := g.CommitParents("HEAD")
+	if err != nil || len(parents) != 2 {
+		t.Fatalf("CommitParents() = %v, %v; want two parents", parents, err)
+	}
+	if _, err := g.Revert(RevertOptions{Commits: []string{"HEAD"}}); err == nil {
+		t.Error("Revert() of a merge without a mainline should fail")
+	}
+	_ = ExecCommand("git", "revert", "--abort").Run()
+	if _, err := g.Revert(RevertOptions{Commits: []string{"HEAD"}, Mainline: 1}); err != nil {
+		t.Fatalf("Revert() of a merge failed: %v", err)
+	}
+	if _, err := os.Stat("feature.txt"); !os.IsNotExist(err) {
+		t.Error("feature.txt should be removed by reverting the merge")
+	}
+}
+
+func TestGitCommands_CherryPick(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "base.txt", "base", "Add base")
+	_ = ExecCommand("git", "checkout", "-q", "-b", "feature").Run()
+	createAndCommitFile(t, g, "a.txt", "a", "Add a")
+	createAndCommitFile(t, g, "b.txt", "b", "Add b")
+	_ = ExecCommand("git", "checkout", "-q", "-").Run()
+	createAndCommitFile(t, g, "main.txt", "main", "Add main")
+
+	if _, err := g.CherryPick(nil); err == nil {
+		t.Error("CherryPick() without commits should fail")
+	}
+	if _, err := g.CherryPick([]string{"feature~1", "feature"}); err != nil {
+		t.Fatalf("CherryPick() failed: %v", err)
+	}
+	commits, err := g.CommitsInRange("feature..HEAD")
+	if err != nil {
+		t.Fatalf("CommitsInRange() failed: %v", err)
+	}
+	if len(commits) != 3 || !strings.HasSuffix(commits[0], "Add b") || !strings.HasSuffix(commits[1], "Add a") {
+		t.Errorf("unexpected cherry-picked commits %q", commits)
+	}
+}
+
+func TestGitCommands_CommitsBetween(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	createAndCommitFile(t, g, "a.txt", "a", "Add a")
+	_ = ExecCommand("git", "checkout", "-q", "-b", "side").Run()
+	createAndCommitFile(t, g, "side.txt", "side", "Side commit")
+	_ = ExecCommand("git", "checkout", "-q", "-").Run()
+	createAndCommitFile(t, g, "b.txt", "b", "Add b")
+
+	subjects := func(commits []string) string {
+		var lines []string
+		for _, commit := range commits {
+			msg, _ := g.GetCommitMessage(commit)
+			lines = append(lines, msg)
+		}
+		return strings.Join(lines, ", ")
+	}
+
+	// The ends may be given in either order, and the root commit has no parent.
+	for _, ends := range [][2]string{{"HEAD~2", "HEAD"}, {"HEAD", "HEAD~2"}} {
+		commits, err := g.CommitsBetween(ends[0], ends[1])
+		if err != nil || subjects(commits) != "Add b, Add a, Initial commit" {
+			t.Errorf("CommitsBetween(%s, %s) = %s, %v", ends[0], ends[1], subjects(commits), err)
+		}
+	}
+	if commits, err := g.CommitsBetween("HEAD~1", "HEAD"); err != nil || subjects(commits) != "Add b, Add a" {
+		t.Errorf("CommitsBetween(HEAD~1, HEAD) = %s, %v", subjects(commits), err)
+	}
+	if _, err := g.CommitsBetween("side", "HEAD"); err == nil {
+		t.Error("CommitsBetween() of diverged commits should fail")
+	}
+}
+
+func TestGitCommands_RepoState(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	if state, err := g.GetRepoState(); err != nil || state.InProgress() {
+		t.Fatalf("GetRepoState() = %+v, %v; want no operation", state, err)
+	}
+
+	// Two branches changing the same file make the rebase stop on a conflict.
+	_ = ExecCommand("git", "checkout", "-q", "-b", "feature").Run()
+	createAndCommitFile(t, g, "file.txt", "feature", "Feature change")
+	createAndCommitFile(t, g, "other.txt", "other", "Other change")
+	_ = ExecCommand("git", "checkout", "-q", "-").Run()
+	createAndCommitFile(t, g, "file.txt", "main", "Main change")
+	_ = ExecCommand("git", "checkout", "-q", "feature").Run()
+	if err := ExecCommand("git", "rebase", "-", "--merge").Run(); err == nil {
+		t.Fatal("expected the rebase to stop on a conflict")
+	}
+
+	state, err := g.GetRepoState()
+	if err != nil {
+		t.Fatalf("GetRepoState() failed: %v", err)
+	}
+	want := RepoState{Operation: OperationRebasing, Step: 1, Total: 2, Branch: "feature"}
+	if state != want {
+		t.Errorf("GetRepoState() = %+v, want %+v", state, want)
+	}
+	if state.String() != "rebasing feature (step 1 of 2)" {
+		t.Errorf("unexpected description %q", state.String())
+	}
+
+	if _, err := g.SkipOperation(); err != nil {
+		t.Fatalf("SkipOperation() failed: %v", err)
+	}
+	if state, _ := g.GetRepoState(); state.InProgress() {
+		t.Errorf("rebase should be finished after skipping the conflict, got %+v", state)
+	}
+
+	createAndCommitFile(t, g, "file.txt", "feature again", "Feature change again")
+	_ = ExecCommand("git", "checkout", "-q", "-").Run()
+	createAndCommitFile(t, g, "file.txt", "main again", "Main change again")
+	if err := ExecCommand("git", "merge", "feature", "--no-edit").Run(); err == nil {
+		t.Fatal("expected the merge to stop on a conflict")
+	}
+	if state, _ := g.GetRepoState(); state.Operation != OperationMerging || state.CanSkip() {
+		t.Errorf("unexpected merge state %+v", state)
+	}
+	if _, err := g.AbortOperation(); err != nil {
+		t.Fatalf("AbortOperation() failed: %v", err)
+	}
+	if state, _ := g.GetRepoState(); state.InProgress() {
+		t.Errorf("merge should be aborted, got %+v", state)
+	}
+}
+
+func TestGitCommands_PredictMerge(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGitCommands()
+	_ = ExecCommand("git", "branch", "feature").Run()
+	createAndCommitFile(t, g, "file.txt", "main\n", "Main change")
+	_ = ExecCommand("git", "checkout", "-q", "feature").Run()
+	createAndCommitFile(t, g, "other.txt", "other\n", "Other change")
+	_ = ExecCommand("git", "checkout", "-q", "-").Run()
+
+	prediction, err := g.PredictMerge("feature")
+	if err != nil {
+		t.Fatalf("PredictMerge() failed: %v", err)
+	}
+	if !prediction.Clean || len(prediction.Conflicts) != 0 {
+		t.Errorf("expected a clean merge, got %+v", prediction)
+	}
+
+	_ = ExecCommand("git", "checkout", "-q", "feature").Run()
+	createAndCommitFile(t, g, "file.txt", "feature\n", "Feature change")
+	_ = ExecCommand("git", "checkout", "-q", "-").Run()
+
+	prediction, err = g.PredictMerge("feature")
+	if err != nil {
+		t.Fatalf("PredictMerge() failed: %v", err)
+	}
+	if prediction.Clean || len(prediction.Conflicts) != 1 || prediction.Conflicts[0].Path != "file.txt" {
+		t.Fatalf("expected a conflict in file.txt, got %+v", prediction)
+	}
+	if hunks := prediction.Conflicts[0].Hunks; len(hunks) != 1 || !strings.Contains(hunks[0], "main\n=======\nfeature") {
+		t.Errorf("unexpected conflict hunks %q", hunks)
+	}
+	if status, _ := ExecCommand("git", "status", "--porcelain").Output(); len(status) != 0 {
+		t.Errorf("PredictMerge() must not touch the working tree, got %q", status)
+	}
+}
+
+func TestParseDiff(t *testing.T) {
+	diff := `commit 1234567
+Author: Jane <jane@example.com>
+
+diff --git a/file.txt b/file.txt
+index 1111111..2222222 100644
+--- a/file.txt
++++ b/file.txt
+@@ -1,3 +1,3 @@
+ one
+-two
++TWO
+ three
+\ No newline at end of file
+diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++hello
+diff --git a/image.png b/image.png
+index 4444444..5555555 100644
+Binary files a/image.png and b/image.png differ
+`
+	parsed := ParseDiff(diff)
+	if len(parsed.Preamble) != 3 || parsed.Preamble[0] != "commit 1234567" {
+		t.Errorf("unexpected preamble %q", parsed.Preamble)
+	}
+	if len(parsed.Files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(parsed.Files))
+	}
+
+	file := parsed.Files[0]
+	if file.Path() != "file.txt" || len(file.Hunks) != 1 {
+		t.Fatalf("unexpected first file %+v", file)
+	}
+	lines := file.Hunks[0].Lines
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %+v", lines)
+	}
+	if lines[1].Kind != DiffRemoved || lines[1].OldLine != 2 || lines[1].NewLine != 0 {
+		t.Errorf("unexpected removed line %+v", lines[1])
+	}
+	if lines[2].Kind != DiffAdded || lines[2].NewLine != 2 || lines[2].Content != "TWO" {
+		t.Errorf("unexpected added line %+v", lines[2])
+	}
+	if lines[3].OldLine != 3 || lines[3].NewLine != 3 || !lines[3].NoNewline {
+		t.Errorf("unexpected context line %+v", lines[3])
+	}
+
+	if !parsed.Files[1].IsNew || parsed.Files[1].Path() != "new.txt" {
+		t.Errorf("expected a new file, got %+v", parsed.Files[1])
+	}
+	if !parsed.Files[2].Binary {
+		t.Errorf("expected a binary file, got %+v", parsed.Files[2])
+	}
+	if parsed.Files[2].OldBlob != "4444444" || parsed.Files[2].NewBlob != "5555555" {
+		t.Errorf("unexpected blobs %q..%q", parsed.Files[2].OldBlob, parsed.Files[2].NewBlob)
+	}
+}
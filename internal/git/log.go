@@ -142,3 +142,42 @@ func getInitials(name string) string {
 
 	return ""
 }
+
+// CommitsInRange returns the commits of a revision range such as
+// "HEAD..feature" as "sha subject", newest first.
+func (g *GitCommands) CommitsInRange(revRange string) ([]string, error) {
+	output, err := g.ShowLog(LogOptions{Format: "%h %s", Branch: revRange})
+	if err != nil {
+		return nil, err
+	}
+	var commits []string
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return commits, nil
+}
+
+// CommitsBetween returns the SHAs of the commits from one commit to another,
+// both included, newest first. Either end may be the older one, but one must
+// be an ancestor of the other.
+func (g *GitCommands) CommitsBetween(from, to string) ([]string, error) {
+	older, newer := from, to
+	if ExecCommand("git", "merge-base", "--is-ancestor", from, to).Run() != nil {
+		if ExecCommand("git", "merge-base", "--is-ancestor", to, from).Run() != nil {
+			return nil, fmt.Errorf("commits %s and %s are not on the same line of history", from, to)
+		}
+		older, newer = to, from
+	}
+
+	args := []string{"rev-list", newer}
+	if parent := g.CommitParent(older); parent != "" {
+		args = append(args, "^"+parent)
+	}
+	output, err := ExecCommand("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %v", err)
+	}
+	return strings.Fields(string(output)), nil
+}
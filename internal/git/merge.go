@@ -7,9 +7,11 @@ import (
 
 // MergeOptions specifies the options for the git merge command.
 type MergeOptions struct {
-	BranchName    string
-	NoFastForward bool
-	Message       string
+	BranchName      string
+	NoFastForward   bool
+	FastForwardOnly bool
+	Squash          bool // Stage the merged changes without committing them.
+	Message         string
 }
 
 // Merge joins two or more development histories together.
@@ -23,9 +25,18 @@ func (g *GitCommands) Merge(options MergeOptions) (string, error) {
 	if options.NoFastForward {
 		args = append(args, "--no-ff")
 	}
+	if options.FastForwardOnly {
+		args = append(args, "--ff-only")
+	}
+	if options.Squash {
+		args = append(args, "--squash")
+	}
 
 	if options.Message != "" {
 		args = append(args, "-m", options.Message)
+	} else {
+		// Use the generated merge message instead of opening an editor.
+		args = append(args, "--no-edit")
 	}
 
 	args = append(args, options.BranchName)
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// MergePrediction is the outcome of merging a branch into HEAD, computed
+// without touching the index or working tree.
+type MergePrediction struct {
+	Clean     bool
+	Conflicts []MergeConflict
+	Messages  []string // Informational messages such as "Auto-merging file".
+}
+
+// MergeConflict is a file that would conflict, with the regions between its
+// conflict markers.
+type MergeConflict struct {
+	Path  string
+	Hunks []string
+}
+
+// PredictMerge runs `git merge-tree --write-tree` between HEAD and branch to
+// find out whether merging (or rebasing onto) it would conflict.
+func (g *GitCommands) PredictMerge(branch string) (*MergePrediction, error) {
+	if branch == "" {
+		return nil, fmt.Errorf("branch name is required")
+	}
+
+	cmd := ExecCommand("git", "merge-tree", "--write-tree", "--name-only", "--messages", "HEAD", branch)
+	output, err := cmd.Output()
+	prediction := &MergePrediction{Clean: err == nil}
+	if err != nil {
+		// Exit status 1 means the merge has conflicts; anything else failed.
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("failed to predict merge (git merge-tree --write-tree requires git 2.38 or newer): %v", err)
+		}
+	}
+
+	// The output is the tree, the conflicted paths, a blank line and the
+	// informational messages.
+	sections := strings.SplitN(strings.TrimRight(string(output), "\n"), "\n\n", 2)
+	header := strings.Split(sections[0], "\n")
+	tree := header[0]
+	if len(sections) == 2 {
+		prediction.Messages = strings.Split(sections[1], "\n")
+	}
+	if prediction.Clean {
+		return prediction, nil
+	}
+
+	for _, path := range header[1:] {
+		if path == "" {
+			continue
+		}
+		conflict := MergeConflict{Path: path}
+		// The written tree contains the file with conflict markers, unless
+		// the conflict is about the file's existence (e.g. modify/delete).
+		if content, err := ExecCommand("git", "cat-file", "-p", tree+":"+path).Output(); err == nil {
+			conflict.Hunks = conflictHunks(string(content))
+		}
+		prediction.Conflicts = append(prediction.Conflicts, conflict)
+	}
+	return prediction, nil
+}
+
+// conflictHunks returns the regions of content from a "<<<<<<<" marker to
+// the matching ">>>>>>>" marker, markers included.
+func conflictHunks(content string) []string {
+	var hunks []string
+	var current []string
+	inConflict := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "<<<<<<< ") {
+			inConflict = true
+		}
+		if inConflict {
+			current = append(current, line)
+		}
+		if inConflict && strings.HasPrefix(line, ">>>>>>> ") {
+			hunks = append(hunks, strings.Join(current, "\n"))
+			current = nil
+			inConflict = false
+		}
+	}
+	return hunks
+}
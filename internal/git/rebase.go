@@ -0,0 +1,228 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RebaseTodoLine is a single instruction of an interactive rebase todo list.
+type RebaseTodoLine struct {
+	Action  string // pick, reword, edit, squash, fixup, drop or exec.
+	SHA     string
+	Subject string // Only informational; git ignores everything after the SHA.
+	Command string // The shell command for exec lines.
+}
+
+// String formats the line the way git expects it in a todo file.
+func (l RebaseTodoLine) String() string {
+	if l.Action == "exec" {
+		return "exec " + l.Command
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s", l.Action, l.SHA, l.Subject))
+}
+
+// GetRebaseTodo returns a pick line for every commit after base up to HEAD,
+// oldest first. An empty base lists the whole history of HEAD.
+func (g *GitCommands) GetRebaseTodo(base string) ([]RebaseTodoLine, error) {
+	revRange := "HEAD"
+	if base != "" {
+		revRange = base + "..HEAD"
+	}
+	cmd := ExecCommand("git", "log", "--reverse", "--format=%H %P%x09%s", revRange)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	var todo []RebaseTodoLine
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		hashes, subject, _ := strings.Cut(line, "\t")
+		fields := strings.Fields(hashes)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("cannot rewrite history containing merge commit %s", fields[0][:7])
+		}
+		todo = append(todo, RebaseTodoLine{Action: "pick", SHA: fields[0], Subject: subject})
+	}
+	return todo, nil
+}
+
+// CommitParent returns the revision to rebase onto in order to rewrite the
+// given commit, or an empty string if the commit is a root commit.
+func (g *GitCommands) CommitParent(commitHash string) string {
+	parent := commitHash + "^"
+	if err := ExecCommand("git", "rev-parse", "--verify", "--quiet", parent).Run(); err != nil {
+		return ""
+	}
+	return parent
+}
+
+// RunRebaseTodo runs `git rebase -i` onto base with the given todo list
+// instead of opening an editor. An empty base rebases the whole history.
+// Local changes are stashed for the duration of the rebase. If the rebase
+// fails it is aborted, so the repository is left as it was.
+func (g *GitCommands) RunRebaseTodo(base string, todo []RebaseTodoLine) (string, error) {
+	var lines []string
+	for _, line := range todo {
+		lines = append(lines, line.String())
+	}
+	todoFile, err := writeTempFile("gitx-rebase-todo-", strings.Join(lines, "\n")+"\n")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.Remove(todoFile)
+	}()
+
+	return runInteractiveRebase(base, "cp "+shellQuote(todoFile), "--no-autosquash")
+}
+
+// Autosquash folds every "fixup!" and "squash!" commit from commitHash on
+// into the commit it targets, without opening an editor. Squash messages are
+// combined the way git does it by default. If the rebase fails it is aborted.
+// Commits of other branches are refused.
+func (g *GitCommands) Autosquash(commitHash string) (string, error) {
+	if err := g.checkOnCurrentBranch(commitHash); err != nil {
+		return "", err
+	}
+	// "true" accepts the todo list git generates for --autosquash as is.
+	return runInteractiveRebase(g.CommitParent(commitHash), "true", "--autosquash")
+}
+
+// SquashCommit folds a commit into its parent, combining their messages.
+func (g *GitCommands) SquashCommit(commitHash string) (string, error) {
+	parent := g.CommitParent(commitHash)
+	if parent == "" {
+		return "", fmt.Errorf("cannot squash the root commit into a parent")
+	}
+	return g.rewriteCommits(commitHash, g.CommitParent(parent), func(todo []RebaseTodoLine) error {
+		todo[1].Action = "squash"
+		return nil
+	})
+}
+
+// DropCommit removes a commit from the history of the current branch.
+func (g *GitCommands) DropCommit(commitHash string) (string, error) {
+	return g.rewriteCommits(commitHash, g.CommitParent(commitHash), func(todo []RebaseTodoLine) error {
+		todo[0].Action = "drop"
+		return nil
+	})
+}
+
+// MoveCommitUp swaps a commit with its child, making it one commit newer.
+func (g *GitCommands) MoveCommitUp(commitHash string) (string, error) {
+	return g.rewriteCommits(commitHash, g.CommitParent(commitHash), func(todo []RebaseTodoLine) error {
+		if len(todo) < 2 {
+			return fmt.Errorf("commit is already the newest one")
+		}
+		todo[0], todo[1] = todo[1], todo[0]
+		return nil
+	})
+}
+
+// MoveCommitDown swaps a commit with its parent, making it one commit older.
+func (g *GitCommands) MoveCommitDown(commitHash string) (string, error) {
+	parent := g.CommitParent(commitHash)
+	if parent == "" {
+		return "", fmt.Errorf("commit is already the oldest one")
+	}
+	return g.rewriteCommits(commitHash, g.CommitParent(parent), func(todo []RebaseTodoLine) error {
+		todo[0], todo[1] = todo[1], todo[0]
+		return nil
+	})
+}
+
+// SplitCommit stops a rebase at the given commit and undoes it with a mixed
+// reset, leaving its changes unstaged so that they can be committed in
+// several pieces. The rebase stays in progress until it is continued.
+func (g *GitCommands) SplitCommit(commitHash string) (string, error) {
+	if g.CommitParent(commitHash) == "" {
+		return "", fmt.Errorf("cannot split the root commit")
+	}
+	output, err := g.rewriteCommits(commitHash, g.CommitParent(commitHash), func(todo []RebaseTodoLine) error {
+		todo[0].Action = "edit"
+		return nil
+	})
+	if err != nil {
+		return output, err
+	}
+
+	resetOutput, err := ExecCommand("git", "reset", "HEAD^").CombinedOutput()
+	if err != nil {
+		_ = ExecCommand("git", "rebase", "--abort").Run()
+		return string(resetOutput), fmt.Errorf("failed to unstage commit and the rebase was rolled back: %v", err)
+	}
+	return output + string(resetOutput), nil
+}
+
+// rewriteCommits rebases the commits after base with a todo list changed by
+// edit, in order to rewrite commitHash. The first todo line is always the
+// oldest commit after base. Commits of other branches are refused.
+func (g *GitCommands) rewriteCommits(commitHash, base string, edit func(todo []RebaseTodoLine) error) (string, error) {
+	if err := g.checkOnCurrentBranch(commitHash); err != nil {
+		return "", err
+	}
+	todo, err := g.GetRebaseTodo(base)
+	if err != nil {
+		return "", err
+	}
+	if len(todo) == 0 {
+		return "", fmt.Errorf("no commits to rewrite")
+	}
+	if err := edit(todo); err != nil {
+		return "", err
+	}
+	return g.RunRebaseTodo(base, todo)
+}
+
+// runInteractiveRebase runs `git rebase -i` onto base (or --root when base is
+// empty) with sequenceEditor as the todo editor, and rolls the rebase back if
+// it fails. Stopping at an "edit" line is not a failure.
+func runInteractiveRebase(base, sequenceEditor string, extraArgs ...string) (string, error) {
+	args := append([]string{"rebase", "-i", "--autostash"}, extraArgs...)
+	if base == "" {
+		args = append(args, "--root")
+	} else {
+		args = append(args, base)
+	}
+
+	cmd := ExecCommand("git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_SEQUENCE_EDITOR="+sequenceEditor,
+		"GIT_EDITOR=true",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		_ = ExecCommand("git", "rebase", "--abort").Run()
+		return string(output), fmt.Errorf("rebase failed and was rolled back: %v", err)
+	}
+
+	return string(output), nil
+}
+
+// writeTempFile writes content to a new temporary file and returns its path.
+func writeTempFile(pattern, content string) (string, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %v", err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write temp file: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write temp file: %v", err)
+	}
+	return file.Name(), nil
+}
+
+// shellQuote quotes s for use in the shell commands git runs for editors and
+// exec lines.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -1,6 +1,11 @@
 package git
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 )
@@ -35,6 +40,41 @@ func (g *GitCommands) GetGitRepoPath() (repoPath string, err error) {
 	return repoPath, nil
 }
 
+// repoRoot returns the top-level directory of the working tree, which paths
+// from `git status` are relative to.
+func repoRoot() (string, error) {
+	root, err := ExecCommand("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find repository root: %v", err)
+	}
+	return strings.TrimSpace(string(root)), nil
+}
+
+// ReadWorkingFile returns up to limit bytes of a working tree file, given
+// its path relative to the repository root, and whether it was cut off.
+func (g *GitCommands) ReadWorkingFile(path string, limit int64) (content string, truncated bool, err error) {
+	root, err := repoRoot()
+	if err != nil {
+		return "", false, err
+	}
+	file, err := os.Open(filepath.Join(root, path))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if int64(len(data)) > limit {
+		return string(data[:limit]), true, nil
+	}
+	return string(data), false, nil
+}
+
 // GetUserName returns the user's name from the git config.
 func (g *GitCommands) GetUserName() (string, error) {
 	cmd := ExecCommand("git", "config", "user.name")
@@ -44,3 +84,19 @@ func (g *GitCommands) GetUserName() (string, error) {
 	}
 	return strings.TrimSpace(string(output)), nil
 }
+
+// GetConfigValue returns the value of a git config key. An unset key is not
+// an error; it yields an empty string.
+func (g *GitCommands) GetConfigValue(key string) (string, error) {
+	cmd := ExecCommand("git", "config", "--get", key)
+	output, err := cmd.Output()
+	if err != nil {
+		// git config exits with status 1 when the key is not set.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -3,6 +3,8 @@ package git
 import (
 	"fmt"
 	"os/exec"
+	"strconv"
+	"strings"
 )
 
 // AddFiles adds file contents to the index (staging area).
@@ -60,6 +62,23 @@ func (g *GitCommands) RemoveFiles(paths []string, cached bool) (string, error) {
 	return string(output), nil
 }
 
+// CleanFiles deletes untracked files, and untracked directories, from the
+// working tree. Ignored files are kept.
+func (g *GitCommands) CleanFiles(paths []string) (string, error) {
+	if len(paths) == 0 {
+		return "", fmt.Errorf("at least one file path is required")
+	}
+
+	args := append([]string{"clean", "-f", "-d", "--"}, paths...)
+	cmd := exec.Command("git", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("failed to clean files: %v", err)
+	}
+
+	return string(output), nil
+}
+
 // MoveFile moves or renames a file, a directory, or a symlink.
 func (g *GitCommands) MoveFile(source, destination string) (string, error) {
 	if source == "" || destination == "" {
@@ -114,28 +133,127 @@ func (g *GitCommands) Restore(options RestoreOptions) (string, error) {
 	return string(output), nil
 }
 
-// Revert is used to record some new commits to reverse the effect of some earlier commits.
-func (g *GitCommands) Revert(commitHash string) (string, error) {
-	if commitHash == "" {
+// RevertOptions specifies the options for reverting commits.
+type RevertOptions struct {
+	Commits  []string // Reverted in the given order, so list the newest first.
+	Mainline int      // Parent number to revert merge commits against.
+	Squash   bool     // Revert all commits in a single commit.
+}
+
+// Revert is used to record some new commits to reverse the effect of some
+// earlier commits. The generated messages are used without opening an
+// editor. On conflicts the revert is left in progress so that it can be
+// resolved and continued. A squashed revert is refused while changes are
+// staged, since they would end up in the revert commit.
+func (g *GitCommands) Revert(options RevertOptions) (string, error) {
+	if len(options.Commits) == 0 {
 		return "", fmt.Errorf("commit hash is required")
 	}
+	if options.Squash {
+		if err := ExecCommand("git", "diff", "--cached", "--quiet").Run(); err != nil {
+			return "", fmt.Errorf("cannot squash the revert while changes are staged; commit or stash them first")
+		}
+	}
+
+	args := []string{"revert", "--no-edit"}
+	if options.Mainline > 0 {
+		args = append(args, "-m", strconv.Itoa(options.Mainline))
+	}
+	if options.Squash {
+		args = append(args, "--no-commit")
+	}
+	args = append(args, options.Commits...)
 
-	cmd := exec.Command("git", "revert", commitHash)
+	cmd := exec.Command("git", args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return string(output), fmt.Errorf("failed to revert commit: %v", err)
 	}
+	if !options.Squash {
+		return string(output), nil
+	}
+
+	message, err := g.squashedRevertMessage(options.Commits)
+	if err != nil {
+		return "", err
+	}
+	return g.Commit(CommitOptions{Message: message})
+}
+
+// CherryPick applies the changes of existing commits to the current branch,
+// in the given order, so list the oldest first. On conflicts the cherry-pick
+// is left in progress so that it can be resolved and continued.
+func (g *GitCommands) CherryPick(commits []string) (string, error) {
+	if len(commits) == 0 {
+		return "", fmt.Errorf("commit hash is required")
+	}
+
+	args := append([]string{"cherry-pick"}, commits...)
+	output, err := ExecCommand("git", args...).CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("failed to cherry-pick commits: %v", err)
+	}
 
 	return string(output), nil
 }
 
+// squashedRevertMessage builds the message of a single commit reverting
+// several commits, listing them the way `git revert` references one.
+func (g *GitCommands) squashedRevertMessage(commits []string) (string, error) {
+	lines := []string{fmt.Sprintf("Revert %d commits", len(commits)), "", "This reverts the following commits:", ""}
+	for _, commit := range commits {
+		output, err := ExecCommand("git", "log", "-1", "--format=%H %s", commit).Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to read commit %s: %v", commit, err)
+		}
+		lines = append(lines, "  "+strings.TrimSpace(string(output)))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// CommitParents returns the parents of a commit as "sha subject", in
+// parent-number order, so that merge commits can be reverted against one.
+func (g *GitCommands) CommitParents(commitHash string) ([]string, error) {
+	output, err := ExecCommand("git", "rev-list", "--parents", "-n", "1", commitHash).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parents of %s: %v", commitHash, err)
+	}
+
+	var parents []string
+	for _, parent := range strings.Fields(string(output))[1:] {
+		subject, err := ExecCommand("git", "log", "-1", "--format=%h %s", parent).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit %s: %v", parent, err)
+		}
+		parents = append(parents, strings.TrimSpace(string(subject)))
+	}
+	return parents, nil
+}
+
+// ResetMode selects what `git reset` does to the index and working tree.
+type ResetMode string
+
+const (
+	// ResetSoft moves HEAD only; the reset commits' changes become staged.
+	ResetSoft ResetMode = "soft"
+	// ResetMixed moves HEAD and resets the index, keeping the working tree.
+	ResetMixed ResetMode = "mixed"
+	// ResetHard moves HEAD and discards all changes to tracked files.
+	ResetHard ResetMode = "hard"
+	// ResetKeep moves HEAD but keeps local changes, refusing if they would be lost.
+	ResetKeep ResetMode = "keep"
+)
+
 // ResetToCommit resets the current HEAD to the specified commit.
-func (g *GitCommands) ResetToCommit(commitHash string) (string, error) {
+func (g *GitCommands) ResetToCommit(commitHash string, mode ResetMode) (string, error) {
 	if commitHash == "" {
 		return "", fmt.Errorf("commit hash is required")
 	}
+	if mode == "" {
+		mode = ResetMixed
+	}
 
-	cmd := exec.Command("git", "reset", "--hard", commitHash)
+	cmd := exec.Command("git", "reset", "--"+string(mode), commitHash)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return string(output), fmt.Errorf("failed to reset to commit: %v", err)
@@ -143,3 +261,50 @@ func (g *GitCommands) ResetToCommit(commitHash string) (string, error) {
 
 	return string(output), nil
 }
+
+// ResetPreview lists what resetting to a commit affects, so that callers can
+// show what each ResetMode would lose.
+type ResetPreview struct {
+	Commits  []string // "sha subject" of the commits that leave the branch.
+	Staged   []string // "status\tpath" of staged changes.
+	Unstaged []string // "status\tpath" of unstaged changes to tracked files.
+	Changed  []string // Paths that differ between HEAD and the commit.
+}
+
+// PreviewReset returns the commits and local changes affected by resetting
+// the current HEAD to the specified commit.
+func (g *GitCommands) PreviewReset(commitHash string) (*ResetPreview, error) {
+	if commitHash == "" {
+		return nil, fmt.Errorf("commit hash is required")
+	}
+
+	run := func(args ...string) ([]string, error) {
+		output, err := ExecCommand("git", args...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview reset: %v", err)
+		}
+		var lines []string
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines, nil
+	}
+
+	var preview ResetPreview
+	var err error
+	if preview.Commits, err = run("log", "--format=%h %s", commitHash+"..HEAD"); err != nil {
+		return nil, err
+	}
+	if preview.Staged, err = run("diff", "--cached", "--name-status", "--no-renames"); err != nil {
+		return nil, err
+	}
+	if preview.Unstaged, err = run("diff", "--name-status", "--no-renames"); err != nil {
+		return nil, err
+	}
+	if preview.Changed, err = run("diff", "--name-only", "--no-renames", commitHash, "HEAD"); err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
@@ -9,17 +9,15 @@ import (
 // Stash represents a single entry in the git stash list.
 type Stash struct {
 	Name    string
+	SHA     string // Stays the same when stashes are pushed or dropped, unlike Name.
 	Branch  string
 	Message string
 }
 
 // GetStashes fetches all stashes and returns them as a slice of Stash structs.
 func (g *GitCommands) GetStashes() ([]*Stash, error) {
-	// Format: stash@{0}
-	// Branch: On master
-	// Message: WIP on master: 52f3a6b feat: add panels
-	// We use a unique delimiter to reliably parse the multi-line output for each stash.
-	format := "%gD%n%gs"
+	// Format: stash@{0}<TAB>sha<TAB>On master: message
+	format := "%gd%x09%H%x09%gs"
 	cmd := ExecCommand("git", "stash", "list", fmt.Sprintf("--format=%s", format))
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -32,13 +30,18 @@ func (g *GitCommands) GetStashes() ([]*Stash, error) {
 	}
 
 	var stashes []*Stash
-	for i, rawStash := range rawStashes {
-		parts := strings.SplitN(rawStash, ": ", 2)
+	for _, rawStash := range rawStashes {
+		fields := strings.SplitN(rawStash, "\t", 3)
+		if len(fields) < 3 {
+			continue // Malformed entry
+		}
+		parts := strings.SplitN(fields[2], ": ", 2)
 		if len(parts) < 2 {
 			continue // Malformed entry
 		}
 		stashes = append(stashes, &Stash{
-			Name:    fmt.Sprintf("stash@{%d}", i),
+			Name:    fields[0],
+			SHA:     fields[1],
 			Branch:  parts[0],
 			Message: parts[1],
 		})
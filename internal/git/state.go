@@ -0,0 +1,186 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RepoOperation is a multi-step git operation that can be in progress.
+type RepoOperation string
+
+const (
+	OperationNone       RepoOperation = ""
+	OperationMerging    RepoOperation = "merging"
+	OperationRebasing   RepoOperation = "rebasing"
+	OperationApplying   RepoOperation = "applying mailbox"
+	OperationCherryPick RepoOperation = "cherry-picking"
+	OperationReverting  RepoOperation = "reverting"
+	OperationBisecting  RepoOperation = "bisecting"
+)
+
+// RepoState describes the operation the repository is in the middle of.
+type RepoState struct {
+	Operation RepoOperation
+	Step      int    // Current step of a rebase or mailbox, starting at 1.
+	Total     int    // Total steps of a rebase or mailbox; 0 when unknown.
+	Branch    string // Branch being rebased, if known.
+}
+
+// InProgress reports whether an operation is in progress.
+func (s RepoState) InProgress() bool {
+	return s.Operation != OperationNone
+}
+
+// CanContinue reports whether the operation has a continue step.
+func (s RepoState) CanContinue() bool {
+	return s.InProgress() && s.Operation != OperationBisecting
+}
+
+// CanSkip reports whether the current step of the operation can be skipped.
+func (s RepoState) CanSkip() bool {
+	return s.InProgress() && s.Operation != OperationMerging
+}
+
+// String describes the state, e.g. "rebasing feature (step 2 of 5)".
+func (s RepoState) String() string {
+	if !s.InProgress() {
+		return ""
+	}
+	description := string(s.Operation)
+	if s.Branch != "" {
+		description += " " + s.Branch
+	}
+	if s.Total > 0 {
+		description += fmt.Sprintf(" (step %d of %d)", s.Step, s.Total)
+	}
+	return description
+}
+
+// GetRepoState inspects the git directory for an in-progress merge, rebase,
+// mailbox, cherry-pick, revert or bisect.
+func (g *GitCommands) GetRepoState() (RepoState, error) {
+	gitDir, err := g.GetGitRepoPath()
+	if err != nil {
+		return RepoState{}, err
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(gitDir, name))
+		return err == nil
+	}
+	readInt := func(name string) int {
+		content, err := os.ReadFile(filepath.Join(gitDir, name))
+		if err != nil {
+			return 0
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(string(content)))
+		return n
+	}
+	readBranch := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(gitDir, name))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimPrefix(strings.TrimSpace(string(content)), "refs/heads/")
+	}
+
+	switch {
+	case exists("rebase-merge"):
+		return RepoState{
+			Operation: OperationRebasing,
+			Step:      readInt("rebase-merge/msgnum"),
+			Total:     readInt("rebase-merge/end"),
+			Branch:    readBranch("rebase-merge/head-name"),
+		}, nil
+	case exists("rebase-apply"):
+		state := RepoState{
+			Operation: OperationRebasing,
+			Step:      readInt("rebase-apply/next"),
+			Total:     readInt("rebase-apply/last"),
+			Branch:    readBranch("rebase-apply/head-name"),
+		}
+		// `git am` marks its rebase-apply directory with an "applying" file.
+		if exists("rebase-apply/applying") {
+			state.Operation = OperationApplying
+			state.Branch = ""
+		}
+		return state, nil
+	case exists("MERGE_HEAD"):
+		return RepoState{Operation: OperationMerging}, nil
+	case exists("CHERRY_PICK_HEAD"):
+		return RepoState{Operation: OperationCherryPick}, nil
+	case exists("REVERT_HEAD"):
+		return RepoState{Operation: OperationReverting}, nil
+	case exists("BISECT_LOG"):
+		return RepoState{Operation: OperationBisecting}, nil
+	}
+	return RepoState{}, nil
+}
+
+// operationCommands are the git subcommands that drive each operation.
+var operationCommands = map[RepoOperation]string{
+	OperationMerging:    "merge",
+	OperationRebasing:   "rebase",
+	OperationApplying:   "am",
+	OperationCherryPick: "cherry-pick",
+	OperationReverting:  "revert",
+	OperationBisecting:  "bisect",
+}
+
+// ContinueOperation continues the in-progress operation after conflicts have
+// been resolved, keeping the prepared commit messages.
+func (g *GitCommands) ContinueOperation() (string, error) {
+	state, err := g.GetRepoState()
+	if err != nil {
+		return "", err
+	}
+	if !state.CanContinue() {
+		return "", fmt.Errorf("there is no operation to continue")
+	}
+	return runOperation(state.Operation, "--continue")
+}
+
+// SkipOperation skips the current step of the in-progress operation.
+func (g *GitCommands) SkipOperation() (string, error) {
+	state, err := g.GetRepoState()
+	if err != nil {
+		return "", err
+	}
+	if !state.CanSkip() {
+		return "", fmt.Errorf("there is no step to skip")
+	}
+	if state.Operation == OperationBisecting {
+		return runOperation(state.Operation, "skip")
+	}
+	return runOperation(state.Operation, "--skip")
+}
+
+// AbortOperation aborts the in-progress operation, restoring the repository
+// to the state before it started.
+func (g *GitCommands) AbortOperation() (string, error) {
+	state, err := g.GetRepoState()
+	if err != nil {
+		return "", err
+	}
+	if !state.InProgress() {
+		return "", fmt.Errorf("there is no operation to abort")
+	}
+	if state.Operation == OperationBisecting {
+		return runOperation(state.Operation, "reset")
+	}
+	return runOperation(state.Operation, "--abort")
+}
+
+// runOperation runs the git command of an operation with GIT_EDITOR set so
+// that prepared commit messages are used as is.
+func runOperation(operation RepoOperation, arg string) (string, error) {
+	cmd := ExecCommand("git", operationCommands[operation], arg)
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("failed to %s %s: %v", strings.TrimPrefix(arg, "--"), operation, err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// mailmapEntryRegex matches the proper name and email at the start of a
+// .mailmap line, e.g. "Jane Doe <jane@example.com> <old@example.com>".
+var mailmapEntryRegex = regexp.MustCompile(`^\s*([^<#]*?)\s*<([^>]+)>`)
+
+// authorScanLimit caps how many commits GetAuthors reads in large repositories.
+const authorScanLimit = 5000
+
+// GetAuthors returns the distinct "Name <email>" identities of everyone who
+// authored a commit, most recent first, followed by any identities that only
+// appear in .mailmap. Names are resolved through .mailmap.
+func (g *GitCommands) GetAuthors() ([]string, error) {
+	output, err := g.ShowLog(LogOptions{Format: "%aN <%aE>", All: true, MaxCount: authorScanLimit})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var authors []string
+	add := func(author string) {
+		author = strings.TrimSpace(author)
+		if author == "" || seen[author] {
+			return
+		}
+		seen[author] = true
+		authors = append(authors, author)
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		add(line)
+	}
+	for _, author := range g.readMailmap() {
+		add(author)
+	}
+	return authors, nil
+}
+
+// readMailmap returns the canonical identities listed in the repository's
+// .mailmap file. A missing file yields no identities.
+func (g *GitCommands) readMailmap() []string {
+	root, err := ExecCommand("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil
+	}
+	file, err := os.Open(filepath.Join(strings.TrimSpace(string(root)), ".mailmap"))
+	if err != nil {
+		return nil
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var identities []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		match := mailmapEntryRegex.FindStringSubmatch(scanner.Text())
+		if match == nil || match[1] == "" {
+			continue
+		}
+		identities = append(identities, fmt.Sprintf("%s <%s>", match[1], match[2]))
+	}
+	return identities
+}
+
+// InterpretTrailers appends trailers such as "Co-authored-by: Jane <jane@example.com>"
+// to a commit message using `git interpret-trailers`, so that they are placed
+// and formatted the way git expects. Identical trailers are not duplicated.
+func (g *GitCommands) InterpretTrailers(message string, trailers []string) (string, error) {
+	args := []string{"interpret-trailers", "--if-exists", "addIfDifferent"}
+	for _, trailer := range trailers {
+		args = append(args, "--trailer", trailer)
+	}
+
+	// Without a final newline git treats the last line as part of the
+	// trailer block and does not separate the trailers with a blank line.
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+
+	cmd := ExecCommand("git", args...)
+	cmd.Stdin = strings.NewReader(message)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("failed to add trailers: %v", err)
+	}
+
+	return string(output), nil
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// WorkingFile describes a file in the working tree.
+type WorkingFile struct {
+	Path    string
+	Size    int64
+	Symlink bool
+	Target  string // The target of a symbolic link.
+}
+
+// DiffUntracked shows an untracked file as a diff that adds all of its
+// lines, using `git diff --no-index /dev/null <path>`.
+func (g *GitCommands) DiffUntracked(path string, options DiffOptions) (string, error) {
+	root, err := repoRoot()
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"diff", "--no-index"}
+	if options.Color {
+		args = append(args, "--color=always")
+	}
+	args = append(args, options.displayArgs()...)
+	cmd := ExecCommand("git", append(args, "--", os.DevNull, path)...)
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 means the files differ, which they always do here.
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return string(output), fmt.Errorf("failed to diff untracked file %s: %v", path, err)
+		}
+	}
+	return string(output), nil
+}
+
+// StatWorkingFile returns the size of a working tree file, given its path
+// relative to the repository root, and the target if it is a symbolic link.
+func (g *GitCommands) StatWorkingFile(path string) (*WorkingFile, error) {
+	root, err := repoRoot()
+	if err != nil {
+		return nil, err
+	}
+	return statFile(root, path)
+}
+
+// statFile describes the file at path below root.
+func statFile(root, path string) (*WorkingFile, error) {
+	fullPath := filepath.Join(root, path)
+	info, err := os.Lstat(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %v", path, err)
+	}
+
+	file := &WorkingFile{Path: path, Size: info.Size()}
+	if info.Mode()&os.ModeSymlink != 0 {
+		file.Symlink = true
+		if file.Target, err = os.Readlink(fullPath); err != nil {
+			return nil, fmt.Errorf("failed to read link %s: %v", path, err)
+		}
+	}
+	return file, nil
+}
+
+// ListUntracked returns the untracked files below dir, a path relative to
+// the repository root, leaving out ignored files.
+func (g *GitCommands) ListUntracked(dir string) ([]WorkingFile, error) {
+	root, err := repoRoot()
+	if err != nil {
+		return nil, err
+	}
+	cmd := ExecCommand("git", "ls-files", "--others", "--exclude-standard", "-z", "--", dir)
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files in %s: %v", dir, err)
+	}
+
+	var files []WorkingFile
+	for _, path := range strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00") {
+		if path == "" {
+			continue
+		}
+		file, err := statFile(root, path)
+		if err != nil {
+			file = &WorkingFile{Path: path}
+		}
+		files = append(files, *file)
+	}
+	return files, nil
+}
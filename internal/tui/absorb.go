@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gitxtui/gitx/internal/git"
+)
+
+// absorbReview holds the state of the review list shown before staged hunks
+// are absorbed into the commits that introduced them.
+type absorbReview struct {
+	plan   *git.AbsorbPlan
+	cursor int
+}
+
+// newAbsorbReview creates a review with the first ambiguous hunk selected,
+// since those are the ones that need a decision.
+func newAbsorbReview(plan *git.AbsorbPlan) absorbReview {
+	r := absorbReview{plan: plan}
+	for i, hunk := range plan.Hunks {
+		if hunk.Ambiguous {
+			r.cursor = i
+			break
+		}
+	}
+	return r
+}
+
+// hunk returns the selected hunk.
+func (r *absorbReview) hunk() *git.AbsorbHunk {
+	return r.plan.Hunks[r.cursor]
+}
+
+// moveCursor moves the hunk selection by delta, clamped to the list.
+func (r *absorbReview) moveCursor(delta int) {
+	r.cursor += delta
+	if r.cursor >= len(r.plan.Hunks) {
+		r.cursor = len(r.plan.Hunks) - 1
+	}
+	if r.cursor < 0 {
+		r.cursor = 0
+	}
+}
+
+// cycleTarget switches the selected hunk to the next (delta 1) or previous
+// (delta -1) candidate commit. Leaving the hunk staged is always a choice.
+func (r *absorbReview) cycleTarget(delta int) {
+	hunk := r.hunk()
+	choices := append(append([]string{}, hunk.Candidates...), "")
+	current := 0
+	for i, choice := range choices {
+		if choice == hunk.Target {
+			current = i
+		}
+	}
+	hunk.Target = choices[(current+delta+len(choices))%len(choices)]
+}
+
+// absorbedCount returns how many hunks will be absorbed.
+func (r *absorbReview) absorbedCount() int {
+	count := 0
+	for _, hunk := range r.plan.Hunks {
+		if hunk.Target != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// targetLabel describes where a hunk goes, e.g. "→ 1a2b3c4 Add parser".
+func (r *absorbReview) targetLabel(hunk *git.AbsorbHunk) string {
+	if hunk.Target == "" {
+		return "(leave staged)"
+	}
+	commit := r.plan.Commits[hunk.Target]
+	return fmt.Sprintf("→ %s %s", commit.SHA[:7], commit.Subject)
+}
@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // Registers the decoders used by image.DecodeConfig.
+	_ "image/jpeg"
+	_ "image/png"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitxtui/gitx/internal/git"
+)
+
+// binarySide is the content of one side of a binary file diff.
+type binarySide struct {
+	data   []byte // The start of the content, or all of it for images.
+	size   int64
+	exists bool
+}
+
+// isImagePath reports whether path has the extension of an image format
+// that can be decoded.
+func isImagePath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".gif":
+		return true
+	}
+	return false
+}
+
+// isMissingBlob reports whether object is the all-zero name git uses for
+// the missing side of an added or deleted file.
+func isMissingBlob(object string) bool {
+	return strings.Trim(object, "0") == ""
+}
+
+// loadBinarySide reads a side of a binary file diff from its blob. The new
+// side of a working tree change is not stored as a blob yet, so it is read
+// from path instead when fromWorkingTree is set.
+func (m Model) loadBinarySide(object, path string, fromWorkingTree bool) binarySide {
+	if isMissingBlob(object) {
+		return binarySide{}
+	}
+	limit := int64(binaryHexDumpBytes)
+	if isImagePath(path) {
+		limit = imagePreviewMaxBytes
+	}
+	if data, size, err := m.git.ReadBlob(object, limit); err == nil {
+		return binarySide{data: data, size: size, exists: true}
+	}
+	if !fromWorkingTree {
+		return binarySide{}
+	}
+	file, err := m.git.StatWorkingFile(path)
+	if err != nil {
+		return binarySide{}
+	}
+	content, _, err := m.git.ReadWorkingFile(path, limit)
+	if err != nil {
+		return binarySide{}
+	}
+	return binarySide{data: []byte(content), size: file.Size, exists: true}
+}
+
+// renderBinaryFile adds the rows that replace git's "Binary files differ"
+// line: the sizes of both sides, the dimensions and a preview of images, and
+// a hex dump of the start of other files.
+func (m Model) renderBinaryFile(rows *renderedRows, file git.FileDiff) {
+	oldSide := m.loadBinarySide(file.OldBlob, file.Path(), false)
+	newSide := m.loadBinarySide(file.NewBlob, file.Path(), true)
+
+	switch {
+	case oldSide.exists && newSide.exists:
+		delta := newSide.size - oldSide.size
+		sign := "+"
+		if delta < 0 {
+			sign, delta = "-", -delta
+		}
+		rows.add(m.theme.NormalText.Render(fmt.Sprintf("Binary file: %s → %s (%s%s)",
+			formatSize(oldSide.size), formatSize(newSide.size), sign, formatSize(delta))), nil)
+	case newSide.exists:
+		rows.add(m.theme.NormalText.Render("Binary file added: "+formatSize(newSide.size)), nil)
+	case oldSide.exists:
+		rows.add(m.theme.NormalText.Render("Binary file deleted: "+formatSize(oldSide.size)), nil)
+	default:
+		rows.add(m.theme.NormalText.Render("Binary files differ"), nil)
+		return
+	}
+
+	if isImagePath(file.Path()) && m.renderImageFile(rows, oldSide, newSide) {
+		return
+	}
+	side := newSide
+	if !side.exists {
+		side = oldSide
+	}
+	for _, line := range hexDump(side.data[:min(len(side.data), binaryHexDumpBytes)]) {
+		rows.add(m.theme.DiffLineNumber.Render(line), nil)
+	}
+}
+
+// renderImageFile adds the dimensions and previews of the sides of an image
+// diff. It reports false when neither side can be decoded.
+func (m Model) renderImageFile(rows *renderedRows, oldSide, newSide binarySide) bool {
+	describe := func(side binarySide) (string, bool) {
+		if !side.exists {
+			return "", false
+		}
+		config, format, err := image.DecodeConfig(bytes.NewReader(side.data))
+		if err != nil {
+			return "unknown", false
+		}
+		return fmt.Sprintf("%d×%d %s", config.Width, config.Height, format), true
+	}
+	oldLabel, oldOK := describe(oldSide)
+	newLabel, newOK := describe(newSide)
+	switch {
+	case oldSide.exists && newSide.exists:
+		rows.add(m.theme.NormalText.Render(fmt.Sprintf("Image: %s → %s", oldLabel, newLabel)), nil)
+	case newSide.exists:
+		rows.add(m.theme.NormalText.Render("Image: "+newLabel), nil)
+	default:
+		rows.add(m.theme.NormalText.Render("Image: "+oldLabel), nil)
+	}
+	if !oldOK && !newOK {
+		return false
+	}
+
+	preview := func(title string, side binarySide, ok bool) {
+		if !ok {
+			return
+		}
+		previewRows := m.renderImagePreview(side.data)
+		if len(previewRows) == 0 {
+			return
+		}
+		rows.add("", nil)
+		if title != "" {
+			rows.add(m.theme.DiffLineNumber.Render(title), nil)
+		}
+		for _, row := range previewRows {
+			rows.add(row, nil)
+		}
+	}
+	if oldSide.exists && newSide.exists {
+		preview("Before", oldSide, oldOK)
+		preview("After", newSide, newOK)
+	} else {
+		preview("", newSide, newOK)
+		preview("", oldSide, oldOK)
+	}
+	return true
+}
+
+// hexDump formats data like `hexdump -C`: offsets, 16 bytes in hex and the
+// printable characters.
+func hexDump(data []byte) []string {
+	var lines []string
+	for offset := 0; offset < len(data); offset += 16 {
+		chunk := data[offset:min(offset+16, len(data))]
+		var hex, text strings.Builder
+		for i := range 16 {
+			if i == 8 {
+				hex.WriteByte(' ')
+			}
+			if i < len(chunk) {
+				fmt.Fprintf(&hex, "%02x ", chunk[i])
+			} else {
+				hex.WriteString("   ")
+			}
+		}
+		for _, b := range chunk {
+			if b >= 0x20 && b < 0x7f {
+				text.WriteByte(b)
+			} else {
+				text.WriteByte('.')
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%08x  %s |%s|", offset, hex.String(), text.String()))
+	}
+	return lines
+}
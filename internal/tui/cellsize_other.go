@@ -0,0 +1,9 @@
+//go:build !unix
+
+package tui
+
+// terminalCellSize returns the size of a terminal cell in pixels. Consoles
+// on this platform don't report it.
+func terminalCellSize() (width, height int) {
+	return 0, 0
+}
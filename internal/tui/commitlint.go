@@ -0,0 +1,195 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gitxtui/gitx/internal/git"
+)
+
+// commitLintMode controls what happens when a commit message breaks a rule.
+type commitLintMode int
+
+const (
+	commitLintWarn commitLintMode = iota
+	commitLintBlock
+	commitLintOff
+)
+
+// conventionalSubjectRegex matches "type(scope)!: description".
+var conventionalSubjectRegex = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+
+// commitLintRules holds the rules that commit messages are checked against.
+// They are read from the `gitx.commitLint.*` git config keys.
+type commitLintRules struct {
+	mode                 commitLintMode
+	subjectMaxLength     int
+	bodyMaxLineLength    int
+	requireBlankLine     bool
+	conventional         bool
+	conventionalTypes    []string
+	conventionalScopes   []string
+	nonImperativeSubject []string
+}
+
+// defaultCommitLintRules returns the 50/72 rules with imperative mood checks.
+func defaultCommitLintRules() commitLintRules {
+	return commitLintRules{
+		mode:              commitLintWarn,
+		subjectMaxLength:  defaultCommitSubjectMaxLength,
+		bodyMaxLineLength: defaultCommitBodyMaxLineLength,
+		requireBlankLine:  true,
+		conventionalTypes: []string{
+			"feat", "fix", "docs", "style", "refactor", "perf",
+			"test", "build", "ci", "chore", "revert",
+		},
+		nonImperativeSubject: []string{
+			"added", "adds", "adding", "fixed", "fixes", "fixing",
+			"updated", "updates", "updating", "removed", "removes", "removing",
+			"changed", "changes", "changing", "created", "creates", "creating",
+			"deleted", "deletes", "deleting", "implemented", "implements", "implementing",
+			"improved", "improves", "improving", "moved", "moves", "moving",
+			"refactored", "refactors", "refactoring", "renamed", "renames", "renaming",
+		},
+	}
+}
+
+// loadCommitLintRules reads the commit lint configuration from git config,
+// falling back to the defaults for keys that are not set.
+func loadCommitLintRules(gc *git.GitCommands) commitLintRules {
+	rules := defaultCommitLintRules()
+	get := func(name string) string {
+		value, err := gc.GetConfigValue("gitx.commitLint." + name)
+		if err != nil {
+			return ""
+		}
+		return value
+	}
+
+	switch strings.ToLower(get("mode")) {
+	case "block":
+		rules.mode = commitLintBlock
+	case "off":
+		rules.mode = commitLintOff
+	case "warn":
+		rules.mode = commitLintWarn
+	}
+	if n, err := strconv.Atoi(get("subjectMaxLength")); err == nil {
+		rules.subjectMaxLength = n
+	}
+	if n, err := strconv.Atoi(get("bodyMaxLineLength")); err == nil {
+		rules.bodyMaxLineLength = n
+	}
+	if b, err := strconv.ParseBool(get("requireBlankLine")); err == nil {
+		rules.requireBlankLine = b
+	}
+	if b, err := strconv.ParseBool(get("conventional")); err == nil {
+		rules.conventional = b
+	}
+	if list := splitConfigList(get("types")); len(list) > 0 {
+		rules.conventionalTypes = list
+	}
+	if list := splitConfigList(get("scopes")); len(list) > 0 {
+		rules.conventionalScopes = list
+	}
+	if value := get("nonImperativeWords"); value != "" {
+		rules.nonImperativeSubject = splitConfigList(value)
+	}
+	return rules
+}
+
+// splitConfigList splits a comma or whitespace separated config value.
+func splitConfigList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// blocksSubmit reports whether violations should prevent the commit.
+func (r commitLintRules) blocksSubmit() bool {
+	return r.mode == commitLintBlock
+}
+
+// lint checks a full commit message and returns a human readable description
+// of every rule it breaks.
+func (r commitLintRules) lint(message string) []string {
+	if r.mode == commitLintOff {
+		return nil
+	}
+
+	var violations []string
+	lines := strings.Split(message, "\n")
+	subject := lines[0]
+
+	if strings.TrimSpace(subject) == "" {
+		return []string{"subject is empty"}
+	}
+	if r.subjectMaxLength > 0 && len([]rune(subject)) > r.subjectMaxLength {
+		violations = append(violations, fmt.Sprintf("subject is %d characters (max %d)", len([]rune(subject)), r.subjectMaxLength))
+	}
+	if strings.HasSuffix(subject, ".") {
+		violations = append(violations, "subject should not end with a period")
+	}
+
+	description := subject
+	if r.conventional {
+		match := conventionalSubjectRegex.FindStringSubmatch(subject)
+		if match == nil {
+			violations = append(violations, `subject should look like "type(scope): description"`)
+		} else {
+			commitType, scope, desc := match[1], match[2], match[4]
+			description = desc
+			if !containsString(r.conventionalTypes, commitType) {
+				violations = append(violations, fmt.Sprintf("unknown type %q (allowed: %s)", commitType, strings.Join(r.conventionalTypes, ", ")))
+			}
+			if scope != "" && len(r.conventionalScopes) > 0 && !containsString(r.conventionalScopes, scope) {
+				violations = append(violations, fmt.Sprintf("unknown scope %q (allowed: %s)", scope, strings.Join(r.conventionalScopes, ", ")))
+			}
+			if strings.TrimSpace(desc) == "" {
+				violations = append(violations, "description after the type is empty")
+			}
+		}
+	}
+
+	if fields := strings.Fields(description); len(fields) > 0 {
+		firstWord := strings.ToLower(strings.Trim(fields[0], ".,:;!"))
+		if containsString(r.nonImperativeSubject, firstWord) {
+			violations = append(violations, fmt.Sprintf("use the imperative mood, not %q", firstWord))
+		}
+	}
+
+	if len(lines) > 1 && r.requireBlankLine && strings.TrimSpace(lines[1]) != "" {
+		violations = append(violations, "subject must be followed by a blank line")
+	}
+
+	if r.bodyMaxLineLength > 0 {
+		for i, line := range lines[1:] {
+			// Lines without spaces are usually URLs or paths that cannot be wrapped.
+			if len([]rune(line)) > r.bodyMaxLineLength && strings.Contains(strings.TrimSpace(line), " ") {
+				violations = append(violations, fmt.Sprintf("line %d is %d characters (wrap at %d)", i+2, len([]rune(line)), r.bodyMaxLineLength))
+			}
+		}
+	}
+
+	return violations
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// buildCommitMessage joins the title and description from the commit pop-up.
+func buildCommitMessage(title, description string) string {
+	if description == "" {
+		return title
+	}
+	return title + "\n\n" + description
+}
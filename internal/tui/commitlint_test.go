@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCommitLintRules_Lint(t *testing.T) {
+	conventional := defaultCommitLintRules()
+	conventional.conventional = true
+	conventional.conventionalScopes = []string{"tui", "git"}
+
+	testCases := []struct {
+		name    string
+		rules   commitLintRules
+		message string
+		want    []string
+	}{
+		{"valid message", defaultCommitLintRules(), "Add commit linting\n\nExplain why.", nil},
+		{"subject too long", defaultCommitLintRules(), strings.Repeat("a", 51), []string{"subject is 51 characters"}},
+		{"trailing period", defaultCommitLintRules(), "Add linting.", []string{"should not end with a period"}},
+		{"imperative mood", defaultCommitLintRules(), "Added linting", []string{`not "added"`}},
+		{"missing blank line", defaultCommitLintRules(), "Add linting\nbody", []string{"followed by a blank line"}},
+		{"body not wrapped", defaultCommitLintRules(), "Add linting\n\n" + strings.Repeat("word ", 20), []string{"line 3 is"}},
+		{"long url is allowed", defaultCommitLintRules(), "Add linting\n\nhttps://example.com/" + strings.Repeat("a", 80), nil},
+		{"conventional ok", conventional, "feat(tui): add linting", nil},
+		{"conventional bad grammar", conventional, "add linting", []string{`"type(scope): description"`}},
+		{"conventional bad type", conventional, "feature: add linting", []string{`unknown type "feature"`}},
+		{"conventional bad scope", conventional, "fix(cli): add linting", []string{`unknown scope "cli"`}},
+		{"conventional imperative", conventional, "fix: fixed linting", []string{`not "fixed"`}},
+		{"mode off", commitLintRules{mode: commitLintOff}, "Added.", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.rules.lint(tc.message)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d violations %q, want %d", len(got), got, len(tc.want))
+			}
+			for i, want := range tc.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("violation %d = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestModel_CommitLintBlocksSubmit(t *testing.T) {
+	m := initialModel()
+	m.mode = modeCommit
+	m.commitLint = defaultCommitLintRules()
+	m.commitLint.mode = commitLintBlock
+	submitted := false
+	m.commitCallback = func(title, description string) tea.Cmd {
+		submitted = true
+		return nil
+	}
+	m.textInput.Focus()
+	m.textInput.SetValue("Added linting.")
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if submitted || updatedModel.(Model).mode != modeCommit {
+		t.Fatal("commit with violations should be blocked")
+	}
+
+	m.textInput.SetValue("Add linting")
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !submitted || updatedModel.(Model).mode != modeNormal {
+		t.Error("valid commit should be submitted")
+	}
+}
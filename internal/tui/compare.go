@@ -0,0 +1,317 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gitxtui/gitx/internal/git"
+)
+
+// diffComparison is the state of the Main panel while it shows the diff
+// between two revisions: a list of the changed files above the combined
+// diff, or the diff of a single file picked from the list.
+type diffComparison struct {
+	from, to string
+	threeDot bool
+	files    []git.ChangedFile
+	diffRows *renderedRows // The combined diff below the file list.
+	cursor   int
+	file     string // The file drilled into, or "" for the file list.
+	oldFile  string // The old path of the file drilled into if it was renamed or copied.
+}
+
+// comparisonLoadedMsg carries the changed files and combined diff of a
+// comparison.
+type comparisonLoadedMsg struct {
+	from, to string
+	threeDot bool
+	files    []git.ChangedFile
+	diffRows *renderedRows
+}
+
+// comparisonHeaderRows is the number of rows above the file list.
+const comparisonHeaderRows = 3
+
+// options returns the diff options selecting the compared revisions.
+func (c *diffComparison) options() git.DiffOptions {
+	return git.DiffOptions{Commit1: c.from, Commit2: c.to, ThreeDot: c.threeDot}
+}
+
+// label describes the comparison in git's range notation, e.g. "a1b2c3d...main".
+func (c *diffComparison) label() string {
+	separator := ".."
+	if c.threeDot {
+		separator = "..."
+	}
+	return shortRevision(c.from) + separator + shortRevision(c.to)
+}
+
+// shortRevision abbreviates full commit hashes and leaves other revisions,
+// such as branch names, as they are.
+func shortRevision(revision string) string {
+	if len(revision) == 40 {
+		return revision[:7]
+	}
+	return revision
+}
+
+// startComparison shows the diff between two revisions in the Main panel
+// and focuses it.
+func (m *Model) startComparison(from, to string) tea.Cmd {
+	m.comparison = &diffComparison{from: from, to: to}
+	m.mainRowLimit = 0
+	m.focusedPanel = MainPanel
+	*m = m.recalculateLayout()
+	m.panels[MainPanel].viewport.GotoTop()
+	return m.updateMainPanel()
+}
+
+// loadComparison returns a command that lists the changed files and renders
+// the combined diff of the comparison.
+func (m *Model) loadComparison(c diffComparison) tea.Cmd {
+	return func() tea.Msg {
+		options := m.diffDisplayOptions(c.options())
+		files, err := m.git.DiffFiles(options)
+		if err != nil {
+			return errMsg{err}
+		}
+		diff, err := m.git.ShowDiff(options)
+		if err != nil {
+			return errMsg{err}
+		}
+		return comparisonLoadedMsg{from: c.from, to: c.to, threeDot: c.threeDot, files: files, diffRows: m.renderDiffRows(diff)}
+	}
+}
+
+// loadComparisonFile returns a command that renders the diff of the file
+// drilled into.
+func (m *Model) loadComparisonFile(c diffComparison) tea.Cmd {
+	return func() tea.Msg {
+		options := c.options()
+		options.Paths = []string{c.file}
+		if c.oldFile != "" {
+			// git only pairs up a rename or copy if both paths are diffed.
+			options.Paths = append(options.Paths, c.oldFile)
+		}
+		diff, err := m.git.ShowDiff(m.diffDisplayOptions(options))
+		if err != nil {
+			return mainContentUpdatedMsg{content: "Error: " + err.Error()}
+		}
+		rows := &renderedRows{}
+		rows.add(m.theme.DiffLineNumber.Render(fmt.Sprintf("%s · esc: back to the file list", c.label())), nil)
+		diffRows := m.renderDiffRows(diff)
+		rows.rows = append(rows.rows, diffRows.rows...)
+		rows.highlight = append(rows.highlight, diffRows.highlight...)
+		m.limitRows(rows)
+		rows.highlightSmall()
+		return mainContentUpdatedMsg{content: rows.String(), rows: rows}
+	}
+}
+
+// showComparisonList returns a command that renders the file list of the
+// comparison above its combined diff.
+func (m *Model) showComparisonList() tea.Cmd {
+	c := *m.comparison
+	return func() tea.Msg {
+		rows := &renderedRows{}
+		title := fmt.Sprintf("Comparing %s: %d files changed", c.label(), len(c.files))
+		rows.add(m.theme.DiffHeader.Render(title), nil)
+		rows.add(m.theme.DiffLineNumber.Render("enter: open file · .: toggle two-dot/three-dot · esc: close"), nil)
+		rows.add("", nil)
+		for i, file := range c.files {
+			rows.add(m.renderChangedFile(file, i == c.cursor), nil)
+		}
+		if c.diffRows != nil && len(c.diffRows.rows) > 0 {
+			rows.add("", nil)
+			rows.rows = append(rows.rows, c.diffRows.rows...)
+			rows.highlight = append(rows.highlight, c.diffRows.highlight...)
+		}
+		m.limitRows(rows)
+		rows.highlightSmall()
+		return mainContentUpdatedMsg{content: rows.String(), rows: rows}
+	}
+}
+
+// renderChangedFile renders an entry of the comparison's file list.
+func (m Model) renderChangedFile(file git.ChangedFile, selected bool) string {
+	path := file.Path
+	if file.OldPath != "" {
+		path = fmt.Sprintf("%s → %s", file.OldPath, file.Path)
+	}
+	if selected {
+		return m.theme.SelectedLine.Render(fmt.Sprintf(" %s %s", file.Status, path))
+	}
+	statusStyle := m.theme.GitUnstaged
+	switch file.Status {
+	case "A", "C":
+		statusStyle = m.theme.GitStaged
+	case "R":
+		statusStyle = m.theme.DiffHunk
+	}
+	return " " + statusStyle.Render(file.Status) + " " + m.theme.NormalText.Render(path)
+}
+
+// handleComparisonKeys handles the keys of the Main panel while it shows a
+// comparison. It returns false for keys it does not handle.
+func (m *Model) handleComparisonKeys(msg tea.KeyMsg) (bool, tea.Cmd) {
+	c := m.comparison
+	vp := &m.panels[MainPanel].viewport
+	switch {
+	case key.Matches(msg, keys.ToggleThreeDot):
+		c.threeDot = !c.threeDot
+		c.file, c.oldFile = "", ""
+		c.cursor = 0
+		vp.GotoTop()
+		return true, m.updateMainPanel()
+
+	case c.file != "":
+		// The file diff scrolls like any other content.
+		return false, nil
+
+	case key.Matches(msg, keys.Up), key.Matches(msg, keys.Down):
+		if key.Matches(msg, keys.Up) && c.cursor > 0 {
+			c.cursor--
+		}
+		if key.Matches(msg, keys.Down) && c.cursor < len(c.files)-1 {
+			c.cursor++
+		}
+		m.scrollToComparisonCursor()
+		return true, m.showComparisonList()
+
+	case key.Matches(msg, keys.OpenComparisonFile):
+		if c.cursor < len(c.files) {
+			c.file, c.oldFile = c.files[c.cursor].Path, c.files[c.cursor].OldPath
+			m.mainRowLimit = 0
+			vp.GotoTop()
+			return true, m.updateMainPanel()
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// closeComparison goes back from a file's diff to the file list, or from
+// the file list to the selected item of the source panel.
+func (m *Model) closeComparison() tea.Cmd {
+	m.mainRowLimit = 0
+	if m.comparison.file != "" {
+		m.comparison.file, m.comparison.oldFile = "", ""
+		m.panels[MainPanel].viewport.GotoTop()
+		m.scrollToComparisonCursor()
+		return m.showComparisonList()
+	}
+	m.comparison = nil
+	m.panels[MainPanel].viewport.GotoTop()
+	return m.updateMainPanel()
+}
+
+// scrollToComparisonCursor scrolls the Main panel so that the selected file
+// of the list is visible.
+func (m *Model) scrollToComparisonCursor() {
+	vp := &m.panels[MainPanel].viewport
+	row := comparisonHeaderRows + m.comparison.cursor
+	if row < vp.YOffset {
+		vp.SetYOffset(row)
+	}
+	if row >= vp.YOffset+vp.Height {
+		vp.SetYOffset(row - vp.Height + 1)
+	}
+}
+
+// baseBranch returns the branch that branch is compared with: the one
+// configured with `gitx.compare.baseBranch`, or the default branch of its
+// remote.
+func (m *Model) baseBranch(branch string) (string, error) {
+	if base, err := m.git.GetConfigValue("gitx.compare.baseBranch"); err == nil && base != "" {
+		return base, nil
+	}
+	return m.git.DefaultBaseBranch(branch)
+}
+
+// compareWithBase renders the comparison of branch with the base branch:
+// the commits unique to each side, the changed files and the combined diff.
+func (m *Model) compareWithBase(branch string) (*renderedRows, error) {
+	base, err := m.baseBranch(branch)
+	if err != nil {
+		return nil, err
+	}
+	comparison, err := m.git.CompareWithBase(base, branch)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := m.git.ShowDiff(m.diffDisplayOptions(git.DiffOptions{Commit1: base, Commit2: branch, ThreeDot: true}))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := &renderedRows{}
+	for _, row := range m.renderBaseComparison(comparison) {
+		rows.add(row, nil)
+	}
+	diffRows := m.renderDiffRows(diff)
+	rows.rows = append(rows.rows, diffRows.rows...)
+	rows.highlight = append(rows.highlight, diffRows.highlight...)
+	return rows, nil
+}
+
+// renderBaseComparison renders the summary shown above the combined diff of
+// a branch and its base.
+func (m Model) renderBaseComparison(c *git.BranchComparison) []string {
+	rows := []string{
+		m.theme.DiffHeader.Render(fmt.Sprintf("%s → %s", c.Branch, c.Base)),
+		m.theme.NormalText.Render("merge base ") + m.theme.CommitSHA.Render(shortRevision(c.MergeBase)),
+		"",
+	}
+
+	commitList := func(title string, commits []string, style lipgloss.Style) {
+		rows = append(rows, m.theme.NormalText.Render(title))
+		for i, commit := range commits {
+			if i == baseComparisonMaxCommits {
+				rows = append(rows, style.Render(fmt.Sprintf("  … and %d more", len(commits)-i)))
+				break
+			}
+			sha, subject, _ := strings.Cut(commit, " ")
+			rows = append(rows, "  "+m.theme.CommitSHA.Render(sha)+" "+style.Render(subject))
+		}
+		rows = append(rows, "")
+	}
+	if len(c.Ahead) == 0 {
+		rows = append(rows, m.theme.NormalText.Render(fmt.Sprintf("%s has no commits that are not on %s.", c.Branch, c.Base)), "")
+	} else {
+		commitList(fmt.Sprintf("↑ %d commit(s) to merge into %s:", len(c.Ahead), c.Base), c.Ahead, m.theme.NormalText)
+	}
+	if len(c.Behind) > 0 {
+		commitList(fmt.Sprintf("↓ %d commit(s) on %s not on %s:", len(c.Behind), c.Base, c.Branch), c.Behind, m.theme.DiffLineNumber)
+	}
+
+	added, deleted, pathWidth := 0, 0, 0
+	for _, file := range c.Files {
+		added += file.Added
+		deleted += file.Deleted
+		pathWidth = max(pathWidth, len([]rune(fileStatPath(file))))
+	}
+	rows = append(rows, m.theme.NormalText.Render(fmt.Sprintf("%d file(s) changed, ", len(c.Files)))+
+		m.theme.DiffAdded.Render(fmt.Sprintf("+%d", added))+" "+m.theme.DiffRemoved.Render(fmt.Sprintf("-%d", deleted)))
+	for _, file := range c.Files {
+		path := fileStatPath(file)
+		row := "  " + m.theme.NormalText.Render(path+strings.Repeat(" ", pathWidth-len([]rune(path)))) + " "
+		if file.Binary {
+			row += m.theme.DiffLineNumber.Render("binary")
+		} else {
+			row += m.theme.DiffAdded.Render(fmt.Sprintf("+%d", file.Added)) + " " + m.theme.DiffRemoved.Render(fmt.Sprintf("-%d", file.Deleted))
+		}
+		rows = append(rows, row)
+	}
+	return append(rows, "")
+}
+
+// fileStatPath returns the path of a file stat, with the old path of a rename.
+func fileStatPath(file git.FileStat) string {
+	if file.OldPath != "" {
+		return file.OldPath + " → " + file.Path
+	}
+	return file.Path
+}
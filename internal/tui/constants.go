@@ -23,6 +23,9 @@ const (
 	titleBarHeight = 2
 	// statusPanelHeight is the fixed height for the status panel.
 	statusPanelHeight = 3
+	// repoStateBannerHeight is added to the status panel while an operation
+	// such as a merge or rebase is in progress.
+	repoStateBannerHeight = 1
 
 	// --- Help View Styling ---
 	// helpTitleMargin is the left margin for the title in the help view.
@@ -36,6 +39,10 @@ const (
 	scrollThumbChar       = "▐"
 	graphNodeChar         = "○"
 	dirExpandedIcon       = "▼ "
+	dirCollapsedIcon      = "▶ "
+	dirStagedIcon         = "●"
+	dirPartlyStagedIcon   = "◐"
+	dirUnstagedIcon       = "○"
 	repoRootNodeName      = "."
 	gitRenameDelimiter    = " -> "
 	initialContentLoading = "Loading..."
@@ -47,6 +54,78 @@ const (
 	// --- Git Status Parsing ---
 	// porcelainStatusPrefixLength is the length of the status prefix in `git status --porcelain`.
 	porcelainStatusPrefixLength = 3
+
+	// --- Commit Linting ---
+	// defaultCommitSubjectMaxLength is the "50" of the 50/72 rule.
+	defaultCommitSubjectMaxLength = 50
+	// defaultCommitBodyMaxLineLength is the "72" of the 50/72 rule.
+	defaultCommitBodyMaxLineLength = 72
+
+	// --- Fuzzy Matching ---
+	// fuzzyMatchScore is awarded for every pattern character that matches.
+	fuzzyMatchScore = 16
+	// fuzzyConsecutiveBonus is added when a match directly follows the previous one.
+	fuzzyConsecutiveBonus = 8
+	// fuzzyWordStartBonus is added when a match starts a word.
+	fuzzyWordStartBonus = 12
+
+	// --- Trailer Picker ---
+	// trailerPickerMaxItems is the number of candidates shown in the trailer picker.
+	trailerPickerMaxItems = 8
+
+	// --- Absorb ---
+	// absorbPreviewMaxLines is the number of diff lines shown for the selected hunk.
+	absorbPreviewMaxLines = 12
+
+	// --- Diff View ---
+	// diffSplitMinWidth is the Main panel width below which the side-by-side
+	// diff falls back to the unified view.
+	diffSplitMinWidth = 100
+	// diffTabWidth is the number of spaces a tab is expanded to in diffs.
+	diffTabWidth = 4
+	// defaultDiffContext is git's default number of context lines.
+	defaultDiffContext = 3
+	// defaultWordDiffRegex matches the words compared by the intra-line diff.
+	defaultWordDiffRegex = `\w+|[^\w\s]`
+	// defaultWordDiffMaxHunkLines is the hunk size above which changed words
+	// are not highlighted.
+	defaultWordDiffMaxHunkLines = 500
+	// wordDiffMaxTokenPairs bounds the work of comparing a pair of lines.
+	wordDiffMaxTokenPairs = 40000
+	// diffMaxRows is the number of rows of a diff shown in the Main panel
+	// before it is cut off; diffLoadMoreRows are added on every "load more".
+	diffMaxRows      = 5000
+	diffLoadMoreRows = 5000
+
+	// --- Syntax Highlighting ---
+	// syntaxEagerMaxRows is the number of rows up to which Main panel content
+	// is highlighted all at once; larger content is highlighted as it scrolls
+	// into view.
+	syntaxEagerMaxRows = 500
+
+	// --- Binary and Image Previews ---
+	// binaryHexDumpBytes is the number of bytes of a binary file shown in hex.
+	binaryHexDumpBytes = 64
+	// imagePreviewMaxBytes is the number of bytes of an image that are read;
+	// larger images get their dimensions but no preview.
+	imagePreviewMaxBytes = 16 << 20
+	// imagePreviewMaxCols and imagePreviewMaxRows bound the cells an image
+	// preview takes up. Rows are also limited by kittyRowDiacritics.
+	imagePreviewMaxCols = 60
+	imagePreviewMaxRows = 20
+	// imagePreviewCellPixels is the width in pixels assumed for a cell when
+	// scaling images down for the kitty graphics protocol.
+	imagePreviewCellPixels = 10
+
+	// --- Compare ---
+	// baseComparisonMaxCommits is the number of commits listed per side when
+	// a branch is compared with its base.
+	baseComparisonMaxCommits = 20
+
+	// --- Reset ---
+	// resetPreviewMaxItems is the number of commits or files listed per section
+	// of the reset preview.
+	resetPreviewMaxItems = 8
 )
 
 // --- Border Characters ---
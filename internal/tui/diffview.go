@@ -0,0 +1,409 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/gitxtui/gitx/internal/git"
+)
+
+// renderDiff parses the output of `git diff` or `git show` and renders it
+// with theme colors, syntax colors and line numbers. See renderDiffRows.
+func (m Model) renderDiff(diff string) string {
+	rows := m.renderDiffRows(diff)
+	rows.highlightRange(0, len(rows.rows))
+	return rows.String()
+}
+
+// renderDiffRows renders a diff with theme colors and line numbers, side by
+// side when the split view is on and the Main panel is wide enough. The rows
+// of files in a known language can be syntax highlighted on demand. Output
+// that cannot be parsed as a diff, such as a combined merge diff, is
+// returned unchanged.
+func (m Model) renderDiffRows(diff string) *renderedRows {
+	rows := &renderedRows{}
+	parsed := git.ParseDiff(stripAnsi(diff))
+	if parsed.Combined || len(parsed.Files) == 0 {
+		for _, line := range strings.Split(diff, "\n") {
+			rows.add(line, nil)
+		}
+		return rows
+	}
+
+	width := m.panels[MainPanel].viewport.Width
+	split := m.diffSplit && width >= diffSplitMinWidth
+
+	for _, line := range parsed.Preamble {
+		rows.add(m.renderPreambleLine(line), nil)
+	}
+	for _, file := range parsed.Files {
+		for _, row := range m.renderFileHeader(file) {
+			rows.add(row, nil)
+		}
+		if file.Binary {
+			m.renderBinaryFile(rows, file)
+			rows.add("", nil)
+			continue
+		}
+		lexer := detectLexer(file.Path(), firstFileLine(file))
+		numberWidth := lineNumberWidth(file)
+		for _, hunk := range file.Hunks {
+			rows.add(m.theme.DiffHunk.Render(hunk.Header), nil)
+			if split {
+				m.renderSplitHunk(rows, hunk, numberWidth, width, lexer)
+			} else {
+				m.renderUnifiedHunk(rows, hunk, numberWidth, lexer)
+			}
+		}
+		rows.add("", nil)
+	}
+	// Drop the blank row after the last file.
+	rows.rows = rows.rows[:len(rows.rows)-1]
+	rows.highlight = rows.highlight[:len(rows.highlight)-1]
+	return rows
+}
+
+// firstFileLine returns the first line of a file when its diff starts there,
+// for shebang detection.
+func firstFileLine(file git.FileDiff) string {
+	if len(file.Hunks) == 0 || len(file.Hunks[0].Lines) == 0 {
+		return ""
+	}
+	if hunk := file.Hunks[0]; hunk.NewStart <= 1 || hunk.OldStart <= 1 {
+		return hunk.Lines[0].Content
+	}
+	return ""
+}
+
+// renderPreambleLine styles a line of a commit header shown before the diff.
+func (m Model) renderPreambleLine(line string) string {
+	if sha, ok := strings.CutPrefix(line, "commit "); ok {
+		return m.theme.CommitSHA.Render("commit " + sha)
+	}
+	return m.theme.NormalText.Render(line)
+}
+
+// renderFileHeader renders the path of a file diff and its notable extended
+// header lines, such as mode changes and renames.
+func (m Model) renderFileHeader(file git.FileDiff) []string {
+	title := file.Path()
+	switch {
+	case file.OldPath != "" && file.NewPath != "" && file.OldPath != file.NewPath:
+		title = fmt.Sprintf("%s → %s", file.OldPath, file.NewPath)
+	case file.IsNew:
+		title += " (new file)"
+	case file.Deleted:
+		title += " (deleted)"
+	}
+
+	rows := []string{m.theme.DiffHeader.Render("▍" + title)}
+	for _, line := range file.Header {
+		// Binary files get a summary in place of git's "Binary files differ".
+		if strings.HasPrefix(line, "index ") || strings.HasPrefix(line, "rename ") ||
+			strings.HasPrefix(line, "similarity index") || strings.HasPrefix(line, "Binary files") {
+			continue
+		}
+		rows = append(rows, m.theme.DiffLineNumber.Render(line))
+	}
+	return rows
+}
+
+// renderUnifiedHunk adds the rows of a hunk as one column with the old and
+// new line numbers in the gutter.
+func (m Model) renderUnifiedHunk(rows *renderedRows, hunk git.DiffHunk, numberWidth int, lexer chroma.Lexer) {
+	spans := m.wordDiff.hunkSpans(hunk.Lines)
+	for i, line := range hunk.Lines {
+		gutter := m.theme.DiffLineNumber.Render(fmt.Sprintf("%s %s │", formatLineNumber(line.OldLine, numberWidth), formatLineNumber(line.NewLine, numberWidth)))
+		var highlight func() string
+		if lexer != nil {
+			highlight = func() string {
+				return gutter + m.renderDiffContent(line, spans[i], -1, lexer)
+			}
+		}
+		rows.add(gutter+m.renderDiffContent(line, spans[i], -1, nil), highlight)
+	}
+}
+
+// renderSplitHunk adds the rows of a hunk as two columns, old on the left and
+// new on the right. Removed and added lines of a change are paired row by
+// row.
+func (m Model) renderSplitHunk(rows *renderedRows, hunk git.DiffHunk, numberWidth, width int, lexer chroma.Lexer) {
+	sideWidth := (width - 1) / 2
+	spans := m.wordDiff.hunkSpans(hunk.Lines)
+	side := func(i int) *splitSide {
+		return &splitSide{line: hunk.Lines[i], spans: spans[i]}
+	}
+	addRow := func(left, right *splitSide) {
+		var highlight func() string
+		if lexer != nil {
+			highlight = func() string {
+				return m.renderSplitRow(left, right, numberWidth, sideWidth, lexer)
+			}
+		}
+		rows.add(m.renderSplitRow(left, right, numberWidth, sideWidth, nil), highlight)
+	}
+
+	var removed, added []int
+	flush := func() {
+		for i := 0; i < len(removed) || i < len(added); i++ {
+			var left, right *splitSide
+			if i < len(removed) {
+				left = side(removed[i])
+			}
+			if i < len(added) {
+				right = side(added[i])
+			}
+			addRow(left, right)
+		}
+		removed, added = nil, nil
+	}
+
+	for i, line := range hunk.Lines {
+		switch line.Kind {
+		case git.DiffRemoved:
+			removed = append(removed, i)
+		case git.DiffAdded:
+			added = append(added, i)
+		default:
+			flush()
+			addRow(side(i), side(i))
+		}
+	}
+	flush()
+}
+
+// splitSide is a line shown on one side of the split view, with its changed
+// words.
+type splitSide struct {
+	line  git.DiffLine
+	spans []diffSpan
+}
+
+// renderSplitRow renders one row of the split view. A nil side is left blank.
+func (m Model) renderSplitRow(left, right *splitSide, numberWidth, sideWidth int, lexer chroma.Lexer) string {
+	cell := func(side *splitSide, number int) string {
+		if side == nil {
+			return strings.Repeat(" ", sideWidth)
+		}
+		gutter := formatLineNumber(number, numberWidth) + " │"
+		contentWidth := sideWidth - lipgloss.Width(gutter)
+		content := m.renderDiffContent(side.line, side.spans, contentWidth, lexer)
+		padding := contentWidth - lipgloss.Width(content)
+		if padding < 0 {
+			padding = 0
+		}
+		return m.theme.DiffLineNumber.Render(gutter) + content + strings.Repeat(" ", padding)
+	}
+
+	oldLine, newLine := 0, 0
+	if left != nil {
+		oldLine = left.line.OldLine
+	}
+	if right != nil {
+		newLine = right.line.NewLine
+	}
+	return cell(left, oldLine) + m.theme.DiffLineNumber.Render("│") + cell(right, newLine)
+}
+
+// renderDiffContent renders the +, - or space prefix and content of a line,
+// truncated to maxWidth when it is not negative. Without a lexer, added and
+// removed lines are drawn in the theme's diff colors; with one, the content
+// gets syntax colors over a tinted background. Either way the changed spans
+// are emphasized.
+func (m Model) renderDiffContent(line git.DiffLine, spans []diffSpan, maxWidth int, lexer chroma.Lexer) string {
+	prefix, style, wordStyle := " ", m.theme.NormalText, m.theme.NormalText
+	var tint lipgloss.TerminalColor
+	switch line.Kind {
+	case git.DiffAdded:
+		prefix, style, wordStyle = "+", m.theme.DiffAdded, m.theme.DiffAddedWord
+		tint = m.theme.DiffAddedLine.GetBackground()
+	case git.DiffRemoved:
+		prefix, style, wordStyle = "-", m.theme.DiffRemoved, m.theme.DiffRemovedWord
+		tint = m.theme.DiffRemovedLine.GetBackground()
+	}
+
+	content := expandTabs(line.Content)
+	var b strings.Builder
+	if lexer != nil {
+		if tint != nil {
+			style = style.Background(tint)
+		}
+		b.WriteString(style.Render(prefix))
+		b.WriteString(m.renderSyntax(lexer, content, spans, tint, wordStyle.GetBackground()))
+	} else {
+		runes := []rune(content)
+		b.WriteString(style.Render(prefix))
+		last := 0
+		for _, span := range spans {
+			b.WriteString(style.Render(string(runes[last:span.start])))
+			b.WriteString(wordStyle.Render(string(runes[span.start:span.end])))
+			last = span.end
+		}
+		b.WriteString(style.Render(string(runes[last:])))
+	}
+
+	text := b.String()
+	if maxWidth >= 0 {
+		text = ansi.Truncate(text, maxWidth, style.Render("…"))
+	}
+	return text
+}
+
+// lineNumberWidth returns the number of digits needed for the largest line
+// number in a file diff.
+func lineNumberWidth(file git.FileDiff) int {
+	largest := 0
+	for _, hunk := range file.Hunks {
+		largest = max(largest, hunk.OldStart+hunk.OldCount, hunk.NewStart+hunk.NewCount)
+	}
+	return len(strconv.Itoa(largest))
+}
+
+// formatLineNumber right-aligns a line number, or returns blanks for 0.
+func formatLineNumber(number, width int) string {
+	if number == 0 {
+		return strings.Repeat(" ", width)
+	}
+	return fmt.Sprintf("%*d", width, number)
+}
+
+// renderUntracked previews an untracked file as a diff that adds all of its
+// lines. Symbolic links get their target and untracked directories a
+// listing of their files.
+func (m Model) renderUntracked(path string) (*renderedRows, error) {
+	rows := &renderedRows{}
+	if strings.HasSuffix(path, "/") {
+		files, err := m.git.ListUntracked(path)
+		if err != nil {
+			return nil, err
+		}
+		var total int64
+		for _, file := range files {
+			total += file.Size
+		}
+		rows.add(m.theme.DiffHeader.Render(fmt.Sprintf("▍%s (untracked directory, %d files, %s)", path, len(files), formatSize(total))), nil)
+		for _, file := range files {
+			name := strings.TrimPrefix(file.Path, path)
+			if file.Symlink {
+				name += " → " + file.Target
+			}
+			rows.add(m.theme.DiffLineNumber.Render(fmt.Sprintf("%10s │ ", formatSize(file.Size)))+m.theme.GitUntracked.Render(name), nil)
+		}
+		return rows, nil
+	}
+
+	file, err := m.git.StatWorkingFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if file.Symlink {
+		rows.add(m.theme.DiffHeader.Render(fmt.Sprintf("▍%s (symbolic link)", path)), nil)
+		rows.add(m.theme.NormalText.Render("→ "+file.Target), nil)
+		return rows, nil
+	}
+
+	diff, err := m.git.DiffUntracked(path, m.diffDisplayOptions(git.DiffOptions{}))
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(diff) == "" {
+		rows.add(m.theme.DiffHeader.Render(fmt.Sprintf("▍%s (new file)", path)), nil)
+		rows.add(m.theme.NormalText.Render("Empty file"), nil)
+		return rows, nil
+	}
+	return m.renderDiffRows(diff), nil
+}
+
+// limitRows cuts off rows beyond the Main panel's row limit, so that a huge
+// diff does not freeze the viewport, and says how to load more of them.
+func (m Model) limitRows(rows *renderedRows) {
+	limit := m.mainRowLimit
+	if limit == 0 {
+		limit = diffMaxRows
+	}
+	if hidden := rows.truncate(limit); hidden > 0 {
+		rows.add("", nil)
+		rows.add(m.theme.DiffHunk.Render(fmt.Sprintf("… %d more rows · %s: load more", hidden, keys.LoadMoreRows.Help().Key)), nil)
+	}
+}
+
+// formatSize formats a number of bytes for humans, e.g. "12.3 KiB".
+func formatSize(bytes int64) string {
+	if bytes < 1024 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	size, units := float64(bytes)/1024, []string{"KiB", "MiB", "GiB", "TiB"}
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", size, units[unit])
+}
+
+// renameThresholds are the --find-renames similarity thresholds cycled
+// through in the Main panel; 0 is git's default.
+var renameThresholds = []int{0, 30, 70, 90}
+
+// diffAlgorithms are the diff algorithms cycled through in the Main panel.
+var diffAlgorithms = []git.DiffAlgorithm{
+	git.DiffAlgorithmDefault, git.DiffAlgorithmPatience, git.DiffAlgorithmHistogram, git.DiffAlgorithmMyers,
+}
+
+// nextInCycle returns the value after current in values, wrapping around.
+func nextInCycle[T comparable](values []T, current T) T {
+	for i, value := range values {
+		if value == current {
+			return values[(i+1)%len(values)]
+		}
+	}
+	return values[0]
+}
+
+// diffContext returns the number of context lines the options produce.
+func diffContext(options git.DiffOptions) int {
+	if options.Context == 0 {
+		return defaultDiffContext
+	}
+	return options.Context
+}
+
+// diffDisplayOptions applies the diff settings of the Main panel to options.
+func (m Model) diffDisplayOptions(options git.DiffOptions) git.DiffOptions {
+	options.IgnoreAllSpace = m.diffOptions.IgnoreAllSpace
+	options.IgnoreSpaceChange = m.diffOptions.IgnoreSpaceChange
+	options.IgnoreBlankLines = m.diffOptions.IgnoreBlankLines
+	options.Context = m.diffOptions.Context
+	options.FindRenames = m.diffOptions.FindRenames
+	options.Algorithm = m.diffOptions.Algorithm
+	return options
+}
+
+// diffOptionsLabel describes the diff settings that differ from git's
+// defaults, e.g. "-w, -U5, renames 70%", for the Main panel title.
+func diffOptionsLabel(options git.DiffOptions) string {
+	var labels []string
+	if options.IgnoreAllSpace {
+		labels = append(labels, "-w")
+	}
+	if options.IgnoreSpaceChange {
+		labels = append(labels, "-b")
+	}
+	if options.IgnoreBlankLines {
+		labels = append(labels, "--ignore-blank-lines")
+	}
+	if options.Context > 0 && options.Context != defaultDiffContext {
+		labels = append(labels, fmt.Sprintf("-U%d", options.Context))
+	}
+	if options.FindRenames > 0 {
+		labels = append(labels, fmt.Sprintf("renames %d%%", options.FindRenames))
+	}
+	if options.Algorithm != git.DiffAlgorithmDefault {
+		labels = append(labels, string(options.Algorithm))
+	}
+	return strings.Join(labels, ", ")
+}
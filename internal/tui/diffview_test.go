@@ -0,0 +1,383 @@
+package tui
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gitxtui/gitx/internal/git"
+)
+
+const testDiff = `diff --git a/file.txt b/file.txt
+index 1111111..2222222 100644
+--- a/file.txt
++++ b/file.txt
+@@ -1,3 +1,4 @@
+ one
+-two
++TWO
++2
+ three
+`
+
+func TestModel_RenderDiff(t *testing.T) {
+	m := initialModel()
+
+	t.Run("unified view numbers both sides", func(t *testing.T) {
+		m.panels[MainPanel].viewport.Width = 120
+		lines := strings.Split(stripAnsi(m.renderDiff(testDiff)), "\n")
+		want := []string{"▍file.txt", "@@ -1,3 +1,4 @@", "1 1 │ one", "2   │-two", "  2 │+TWO", "  3 │+2", "3 4 │ three"}
+		if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+			t.Errorf("unexpected unified diff:\n%s", strings.Join(lines, "\n"))
+		}
+	})
+
+	t.Run("split view pairs removed and added lines", func(t *testing.T) {
+		m.diffSplit = true
+		m.panels[MainPanel].viewport.Width = 121
+		lines := strings.Split(stripAnsi(m.renderDiff(testDiff)), "\n")
+		if len(lines) != 6 {
+			t.Fatalf("expected 6 rows, got %d:\n%s", len(lines), strings.Join(lines, "\n"))
+		}
+		left, right, _ := strings.Cut(lines[3], "│-two")
+		if left != "2 " || !strings.Contains(right, "2 │+TWO") {
+			t.Errorf("removed and added line should share a row, got %q", lines[3])
+		}
+		if !strings.HasPrefix(strings.TrimSpace(lines[4]), "│3 │+2") {
+			t.Errorf("added line without a counterpart should leave the left blank, got %q", lines[4])
+		}
+		for _, line := range lines[2:] {
+			if width := len([]rune(line)); width != 121 {
+				t.Errorf("split rows should fill the panel, got width %d for %q", width, line)
+			}
+		}
+	})
+
+	t.Run("narrow panel falls back to unified", func(t *testing.T) {
+		m.diffSplit = true
+		m.panels[MainPanel].viewport.Width = diffSplitMinWidth - 1
+		if rendered := stripAnsi(m.renderDiff(testDiff)); !strings.Contains(rendered, "2   │-two") {
+			t.Errorf("expected the unified view, got:\n%s", rendered)
+		}
+	})
+
+	t.Run("non-diff output is unchanged", func(t *testing.T) {
+		if got := m.renderDiff("Untracked file"); got != "Untracked file" {
+			t.Errorf("renderDiff() = %q", got)
+		}
+	})
+}
+
+func TestWordDiffConfig_HunkSpans(t *testing.T) {
+	config := wordDiffConfig{wordRegex: regexp.MustCompile(defaultWordDiffRegex), maxHunkLines: defaultWordDiffMaxHunkLines}
+	lines := []git.DiffLine{
+		{Kind: git.DiffRemoved, Content: "return foo(a, b)"},
+		{Kind: git.DiffRemoved, Content: "xyz"},
+		{Kind: git.DiffAdded, Content: "return bar(a, b, c)"},
+		{Kind: git.DiffAdded, Content: "completely different"},
+		{Kind: git.DiffContext, Content: "}"},
+	}
+	spanText := func(line string, spans []diffSpan) []string {
+		var texts []string
+		for _, span := range spans {
+			texts = append(texts, string([]rune(line)[span.start:span.end]))
+		}
+		return texts
+	}
+
+	spans := config.hunkSpans(lines)
+	if got := spanText(lines[0].Content, spans[0]); !reflect.DeepEqual(got, []string{"foo"}) {
+		t.Errorf("removed line spans = %q, want [foo]", got)
+	}
+	if got := spanText(lines[2].Content, spans[2]); !reflect.DeepEqual(got, []string{"bar", ", c"}) {
+		t.Errorf("added line spans = %q, want [bar , c]", got)
+	}
+	if len(spans[1]) != 0 || len(spans[3]) != 0 {
+		t.Errorf("lines with no words in common should not be highlighted, got %v and %v", spans[1], spans[3])
+	}
+
+	config.maxHunkLines = len(lines) - 1
+	if spans := config.hunkSpans(lines); spans != nil {
+		t.Errorf("hunks over the size limit should not be highlighted, got %v", spans)
+	}
+}
+
+func TestDetectLexer(t *testing.T) {
+	tests := []struct {
+		path, firstLine, want string
+	}{
+		{"main.go", "package main", "Go"},
+		{"scripts/build", "#!/usr/bin/env python3", "Python"},
+		{"run", "#!/bin/bash -e", "Bash"},
+		{"notes", "just text", ""},
+	}
+	for _, tt := range tests {
+		lexer := detectLexer(tt.path, tt.firstLine)
+		got := ""
+		if lexer != nil {
+			got = lexer.Config().Name
+		}
+		if got != tt.want {
+			t.Errorf("detectLexer(%q, %q) = %q, want %q", tt.path, tt.firstLine, got, tt.want)
+		}
+	}
+}
+
+func TestModel_RenderDiffRows_LazyHighlight(t *testing.T) {
+	m := initialModel()
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,0 +1,600 @@\n" +
+		strings.Repeat("+x := 1 // comment\n", syntaxEagerMaxRows+100)
+	rows := m.renderDiffRows(diff)
+
+	rows.highlightSmall()
+	last := len(rows.rows) - 1
+	if rows.highlight[last] == nil {
+		t.Fatal("large diffs should not be highlighted up front")
+	}
+	plain := rows.rows[last]
+	if !rows.highlightRange(last-10, last+1) || rows.highlight[last] != nil || rows.highlight[last-11] == nil {
+		t.Error("highlightRange should highlight only the requested rows")
+	}
+	if stripAnsi(rows.rows[last]) != stripAnsi(plain) {
+		t.Errorf("highlighting should not change the text, got %q want %q", stripAnsi(rows.rows[last]), stripAnsi(plain))
+	}
+	if rows.highlightRange(last-10, last+1) {
+		t.Error("rows should only be highlighted once")
+	}
+}
+
+func TestModel_HandleMainPanelKeys_DiffOptions(t *testing.T) {
+	m := initialModel()
+	press := func(keys string) {
+		for _, r := range keys {
+			m.handleMainPanelKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+
+	press("w++ra")
+	want := "-w, -U5, renames 30%, patience"
+	if got := diffOptionsLabel(m.diffOptions); got != want {
+		t.Errorf("diffOptionsLabel() = %q, want %q", got, want)
+	}
+
+	press("w-------aaa")
+	if m.diffOptions.Context != 1 {
+		t.Errorf("context should not go below 1, got %d", m.diffOptions.Context)
+	}
+	if m.diffOptions.IgnoreAllSpace || m.diffOptions.Algorithm != git.DiffAlgorithmDefault {
+		t.Errorf("toggles should cycle back to their defaults, got %+v", m.diffOptions)
+	}
+
+	options := m.diffDisplayOptions(git.DiffOptions{Cached: true, Paths: []string{"file.txt"}})
+	if !options.Cached || len(options.Paths) != 1 || options.Context != 1 {
+		t.Errorf("diffDisplayOptions() = %+v", options)
+	}
+}
+
+func TestModel_Comparison(t *testing.T) {
+	m := initialModel()
+	m.focusedPanel = MainPanel
+	m.panels[MainPanel].viewport.Height = 10
+	m.comparison = &diffComparison{
+		from: strings.Repeat("a", 40), to: "feature", threeDot: true,
+		files: []git.ChangedFile{{Status: "M", Path: "one.go"}, {Status: "R", Path: "new.go", OldPath: "old.go"}},
+	}
+	if got := m.comparison.label(); got != "aaaaaaa...feature" {
+		t.Errorf("label() = %q", got)
+	}
+
+	down := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}}
+	cmd := m.handleMainPanelKeys(down)
+	if m.comparison.cursor != 1 || cmd == nil {
+		t.Fatalf("down should move the file cursor, got %d", m.comparison.cursor)
+	}
+	msg := cmd().(mainContentUpdatedMsg)
+	lines := strings.Split(stripAnsi(msg.content), "\n")
+	if len(lines) != comparisonHeaderRows+2 || lines[comparisonHeaderRows+1] != " R old.go → new.go" {
+		t.Errorf("unexpected file list:\n%s", stripAnsi(msg.content))
+	}
+
+	m.handleMainPanelKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.comparison.file != "new.go" || m.comparison.oldFile != "old.go" {
+		t.Fatalf("enter should open the selected file, got %q from %q", m.comparison.file, m.comparison.oldFile)
+	}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if m.comparison == nil || m.comparison.file != "" {
+		t.Fatal("esc should go back to the file list first")
+	}
+	m.closeComparison()
+	if m.comparison != nil {
+		t.Error("closing the file list should end the comparison")
+	}
+}
+
+func TestModel_RenderBaseComparison(t *testing.T) {
+	m := initialModel()
+	rows := m.renderBaseComparison(&git.BranchComparison{
+		Base: "main", Branch: "feature", MergeBase: strings.Repeat("b", 40),
+		Ahead: []string{"abc1234 Add feature"},
+		Files: []git.FileStat{{Path: "a.go", Added: 3, Deleted: 1}, {Path: "logo.png", Binary: true}},
+	})
+	got := stripAnsi(strings.Join(rows, "\n"))
+	for _, want := range []string{
+		"feature → main", "merge base bbbbbbb", "↑ 1 commit(s) to merge into main:", "  abc1234 Add feature",
+		"2 file(s) changed, +3 -1", "  a.go     +3 -1", "  logo.png binary",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("comparison should contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "↓") {
+		t.Errorf("a branch that is not behind should not list base commits:\n%s", got)
+	}
+}
+
+func TestHexDump(t *testing.T) {
+	got := hexDump([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR\x01"))
+	want := []string{
+		"00000000  89 50 4e 47 0d 0a 1a 0a  00 00 00 0d 49 48 44 52  |.PNG........IHDR|",
+		"00000010  01                                                |.|",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("hexDump() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestModel_RenderImageFile(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for x := range 40 {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 255})
+	}
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		t.Fatal(err)
+	}
+	side := binarySide{data: encoded.Bytes(), size: int64(encoded.Len()), exists: true}
+
+	m := initialModel()
+	m.panels[MainPanel].viewport.Width = 80
+	// Without a terminal the sixel preview falls back to blocks.
+	for _, mode := range []imagePreviewMode{imagePreviewOff, imagePreviewBlocks, imagePreviewKitty, imagePreviewSixel} {
+		m.imagePreview = mode
+		rows := &renderedRows{}
+		if !m.renderImageFile(rows, binarySide{}, side) {
+			t.Fatalf("%s: renderImageFile() should decode a PNG", mode)
+		}
+		if got := stripAnsi(rows.rows[0]); got != "Image: 40×20 png" {
+			t.Errorf("%s: got %q, want the dimensions", mode, got)
+		}
+		// 40×20 pixels fit in 40×10 cells.
+		wantRows := map[imagePreviewMode]int{imagePreviewOff: 1, imagePreviewBlocks: 12, imagePreviewKitty: 12, imagePreviewSixel: 12}[mode]
+		if len(rows.rows) != wantRows {
+			t.Errorf("%s: got %d rows, want %d", mode, len(rows.rows), wantRows)
+		}
+	}
+
+	rows := &renderedRows{}
+	if m.renderImageFile(rows, binarySide{}, binarySide{data: []byte("not an image"), exists: true}) {
+		t.Error("renderImageFile() should report undecodable images")
+	}
+}
+
+func TestSixelPreview(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := range 4 {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 255})
+	}
+
+	lines := sixelPreview(img, 4, 2, 10, 20)
+	if len(lines) != 2 || lines[0] != "    " {
+		t.Fatalf("unexpected rows %q", lines)
+	}
+	// 4×2 pixels fit in 40×20 of the 40×40 pixels of the cells; the
+	// transparent lower half is not painted.
+	want := "    \x1b7\x1b[1A\x1b[4D\x1bP0;1;0q\"1;1;40;20#180;2;100;0;0!40~-#180!40N---\x1b\\\x1b8"
+	if lines[1] != want {
+		t.Errorf("got last row %q, want %q", lines[1], want)
+	}
+
+	// Images whose first row is scrolled out of view are removed.
+	shown := clipSixelImages([]string{lines[1], lines[0], lines[1]})
+	if shown[0] != "    " || shown[2] != lines[1] {
+		t.Errorf("unexpected clipped rows %q", shown)
+	}
+
+	vp := viewport.New(10, 2)
+	vp.SetContent(strings.Join(lines, "\n"))
+	box := renderBox("Main", lipgloss.NewStyle(), initialModel().theme.ActiveBorder, vp, lipgloss.NewStyle(), 12, 4, false)
+	if !strings.Contains(box, lines[1][4:]) {
+		t.Errorf("the image should be drawn unchanged, got %q", box)
+	}
+}
+
+func TestPreviewCells(t *testing.T) {
+	tests := []struct {
+		width, height, maxCols int
+		cols, rows             int
+	}{
+		{40, 20, 80, 40, 10},
+		{1000, 100, 80, imagePreviewMaxCols, 3},
+		{100, 1000, 80, 4, imagePreviewMaxRows},
+		{8, 8, 4, 4, 2},
+	}
+	for _, tt := range tests {
+		if cols, rows := previewCells(tt.width, tt.height, tt.maxCols); cols != tt.cols || rows != tt.rows {
+			t.Errorf("previewCells(%d, %d, %d) = %d, %d; want %d, %d", tt.width, tt.height, tt.maxCols, cols, rows, tt.cols, tt.rows)
+		}
+	}
+}
+
+func TestDetectImagePreview(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want imagePreviewMode
+	}{
+		{map[string]string{"TERM": "xterm-kitty"}, imagePreviewKitty},
+		{map[string]string{"TERM_PROGRAM": "ghostty", "COLORTERM": "truecolor"}, imagePreviewKitty},
+		{map[string]string{"TERM": "foot", "COLORTERM": "truecolor"}, imagePreviewSixel},
+		{map[string]string{"TERM_PROGRAM": "WezTerm", "COLORTERM": "truecolor"}, imagePreviewSixel},
+		{map[string]string{"TERM": "alacritty", "COLORTERM": "truecolor"}, imagePreviewBlocks},
+		{map[string]string{"TERM": "xterm-256color"}, imagePreviewOff},
+	}
+	for _, tt := range tests {
+		getenv := func(name string) string { return tt.env[name] }
+		if got := detectImagePreview(getenv); got != tt.want {
+			t.Errorf("detectImagePreview(%v) = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestModel_RenderDiffRows_Binary(t *testing.T) {
+	m := initialModel()
+	diff := "diff --git a/data.bin b/data.bin\nindex 0000000..0000000\nBinary files a/data.bin and b/data.bin differ\n"
+	got := stripAnsi(m.renderDiff(diff))
+	if want := "▍data.bin\nBinary files differ"; got != want {
+		t.Errorf("renderDiff() = %q, want %q", got, want)
+	}
+}
+
+func TestModel_LimitRows(t *testing.T) {
+	m := initialModel()
+	rows := &renderedRows{}
+	for range diffMaxRows + 10 {
+		rows.add("row", nil)
+	}
+	m.limitRows(rows)
+	if len(rows.rows) != diffMaxRows+2 || !strings.Contains(stripAnsi(rows.rows[len(rows.rows)-1]), "10 more rows") {
+		t.Errorf("limitRows() left %d rows ending in %q", len(rows.rows), rows.rows[len(rows.rows)-1])
+	}
+
+	m.handleMainPanelKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	if m.mainRowLimit != diffMaxRows+diffLoadMoreRows {
+		t.Errorf("load more should raise the limit, got %d", m.mainRowLimit)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"github.com/gitxtui/gitx/internal/git"
 )
 
 // Node represents a file or directory within the file tree structure.
@@ -14,6 +16,33 @@ type Node struct {
 	path      string // Full path relative to the repository root.
 	isRenamed bool
 	children  []*Node
+	counts    lineCounts // Totals of all files below a directory.
+}
+
+// lineCounts is the number of lines added and deleted by the staged and the
+// unstaged changes of a file or directory.
+type lineCounts struct {
+	stagedAdded, stagedDeleted     int
+	unstagedAdded, unstagedDeleted int
+}
+
+// plus returns the sum of two line counts.
+func (c lineCounts) plus(other lineCounts) lineCounts {
+	return lineCounts{
+		stagedAdded:     c.stagedAdded + other.stagedAdded,
+		stagedDeleted:   c.stagedDeleted + other.stagedDeleted,
+		unstagedAdded:   c.unstagedAdded + other.unstagedAdded,
+		unstagedDeleted: c.unstagedDeleted + other.unstagedDeleted,
+	}
+}
+
+// formatLineCounts formats added and deleted lines as "+3 -1", or "" when
+// nothing changed.
+func formatLineCounts(added, deleted int) string {
+	if added == 0 && deleted == 0 {
+		return ""
+	}
+	return fmt.Sprintf("+%d -%d", added, deleted)
 }
 
 // BuildTree parses the output of `git status --porcelain` to construct a file tree.
@@ -41,7 +70,10 @@ func BuildTree(gitStatus string) *Node {
 			}
 		}
 
-		parts := strings.Split(fullPath, string(filepath.Separator))
+		// Untracked directories are listed once, as "dir/", and shown as a
+		// single entry rather than a directory with an unnamed file.
+		isUntrackedDir := strings.HasSuffix(fullPath, "/")
+		parts := strings.Split(strings.TrimSuffix(fullPath, "/"), string(filepath.Separator))
 		currentNode := root
 		for i, part := range parts {
 			childNode := currentNode.findChild(part)
@@ -57,6 +89,9 @@ func BuildTree(gitStatus string) *Node {
 			currentNode = childNode
 
 			if i == len(parts)-1 { // Leaf node (file)
+				if isUntrackedDir {
+					currentNode.name += "/"
+				}
 				currentNode.status = status
 				currentNode.path = fullPath // Overwrite with the full path from git
 				currentNode.isRenamed = isRenamed
@@ -69,9 +104,330 @@ func BuildTree(gitStatus string) *Node {
 	return root
 }
 
-// Render traverses the tree and returns a slice of formatted strings for display.
-func (n *Node) Render(theme Theme) []string {
-	return n.renderRecursive("", theme)
+// fileViewMode is how the Files panel lists the changed files.
+type fileViewMode int
+
+const (
+	fileViewTree fileViewMode = iota
+	fileViewFlat
+	fileViewGrouped
+)
+
+// fileSection is a group of the grouped Files view, like the sections of
+// `git status`.
+type fileSection struct {
+	title string
+	// status returns the status a file is listed with in the section, or ""
+	// if it does not belong to it.
+	status func(status string) string
+	// counts returns the line counts shown for a file of the section.
+	counts func(c lineCounts) lineCounts
+}
+
+// fileSections are the sections of the grouped Files view. A file with both
+// staged and unstaged changes is listed in both sections with only the
+// changes of each, so that its rows show and stage those changes.
+var fileSections = []fileSection{
+	{
+		title: "Conflicted",
+		status: func(status string) string {
+			if !isConflicted(status) {
+				return ""
+			}
+			return status
+		},
+		counts: func(c lineCounts) lineCounts { return c },
+	},
+	{
+		title: "Staged",
+		status: func(status string) string {
+			if isConflicted(status) || status[0] == ' ' || status[0] == '?' {
+				return ""
+			}
+			return status[:1] + " "
+		},
+		counts: func(c lineCounts) lineCounts {
+			return lineCounts{stagedAdded: c.stagedAdded, stagedDeleted: c.stagedDeleted}
+		},
+	},
+	{
+		title: "Unstaged",
+		status: func(status string) string {
+			if isConflicted(status) || status == "??" || status[1] == ' ' {
+				return ""
+			}
+			return " " + status[1:]
+		},
+		counts: func(c lineCounts) lineCounts {
+			return lineCounts{unstagedAdded: c.unstagedAdded, unstagedDeleted: c.unstagedDeleted}
+		},
+	},
+	{
+		title: "Untracked",
+		status: func(status string) string {
+			if status != "??" {
+				return ""
+			}
+			return status
+		},
+		counts: func(c lineCounts) lineCounts { return c },
+	},
+}
+
+// isConflicted reports whether a two-character status is an unmerged path.
+func isConflicted(status string) bool {
+	return strings.Contains(status, "U") || status == "AA" || status == "DD"
+}
+
+// Render traverses the tree and returns a slice of formatted strings for
+// display, starting with a summary row for the repository root. The contents
+// of the directories whose paths are in collapsed are left out.
+func (n *Node) Render(theme Theme, collapsed map[string]bool) []string {
+	if len(n.children) == 0 {
+		return nil
+	}
+	return append([]string{n.summaryLine()}, n.renderRecursive("", theme, collapsed)...)
+}
+
+// RenderFlat returns the same lines as Render for a plain list of the
+// changed files, sorted by path.
+func (n *Node) RenderFlat() []string {
+	if len(n.children) == 0 {
+		return nil
+	}
+	lines := []string{n.summaryLine()}
+	for _, file := range n.sortedFiles() {
+		lines = append(lines, fmt.Sprintf("\t%s\t%s\t%s\t%s\t\t", file.status, file.path, file.path, file.counts.columns()))
+	}
+	return lines
+}
+
+// RenderGrouped returns the same lines as Render for a list of the changed
+// files in the sections of fileSections. Section titles have no path.
+func (n *Node) RenderGrouped(theme Theme) []string {
+	if len(n.children) == 0 {
+		return nil
+	}
+	lines := []string{n.summaryLine()}
+	files := n.sortedFiles()
+	for _, section := range fileSections {
+		var sectionLines []string
+		for _, file := range files {
+			if len(file.status) < 2 {
+				continue
+			}
+			if status := section.status(file.status); status != "" {
+				sectionLines = append(sectionLines, fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t\t",
+					theme.Tree.Prefix, status, file.path, file.path, section.counts(file.counts).columns()))
+			}
+		}
+		if len(sectionLines) > 0 {
+			lines = append(lines, fmt.Sprintf("\t\t%s (%d)\t\t\t\t\t", section.title, len(sectionLines)))
+			lines = append(lines, sectionLines...)
+		}
+	}
+	return lines
+}
+
+// summaryLine renders the row for the repository root that sums up all
+// changes.
+func (n *Node) summaryLine() string {
+	summary := "1 changed file"
+	if files := n.countFiles(); files != 1 {
+		summary = fmt.Sprintf("%d changed files", files)
+	}
+	return fmt.Sprintf("\t\t%s\t%s\t%s\t\t%s", summary, n.path, n.counts.columns(), n.stageIcon())
+}
+
+// sortedFiles returns the files below a node sorted by path.
+func (n *Node) sortedFiles() []*Node {
+	var files []*Node
+	n.walkFiles(func(file *Node) {
+		files = append(files, file)
+	})
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].path < files[j].path
+	})
+	return files
+}
+
+// SetLineCounts sets the lines added and deleted in every file from the
+// `git diff --numstat` output of the staged and unstaged changes, and totals
+// them up for the directories.
+func (n *Node) SetLineCounts(staged, unstaged []git.FileStat) {
+	counts := make(map[string]lineCounts)
+	for _, stat := range staged {
+		c := counts[stat.Path]
+		c.stagedAdded += stat.Added
+		c.stagedDeleted += stat.Deleted
+		counts[stat.Path] = c
+	}
+	for _, stat := range unstaged {
+		c := counts[stat.Path]
+		c.unstagedAdded += stat.Added
+		c.unstagedDeleted += stat.Deleted
+		counts[stat.Path] = c
+	}
+	n.sumLineCounts(counts)
+}
+
+// sumLineCounts sets the line counts of a file, or of all files below a
+// directory, and returns them.
+func (n *Node) sumLineCounts(counts map[string]lineCounts) lineCounts {
+	if len(n.children) == 0 {
+		n.counts = counts[n.path]
+		return n.counts
+	}
+	n.counts = lineCounts{}
+	for _, child := range n.children {
+		n.counts = n.counts.plus(child.sumLineCounts(counts))
+	}
+	return n.counts
+}
+
+// countFiles returns the number of files below a node.
+func (n *Node) countFiles() int {
+	if len(n.children) == 0 {
+		return 1
+	}
+	count := 0
+	for _, child := range n.children {
+		count += child.countFiles()
+	}
+	return count
+}
+
+// columns returns the staged and unstaged line counts as two tab-delimited
+// columns of a rendered line.
+func (c lineCounts) columns() string {
+	return formatLineCounts(c.stagedAdded, c.stagedDeleted) + "\t" + formatLineCounts(c.unstagedAdded, c.unstagedDeleted)
+}
+
+// aggregateStatus combines the statuses of all files below a directory into
+// one: each side shows the change all files share, "M" for a mix of changes
+// and nothing when no file has changes on that side. It is "??" when all
+// files are untracked and "UU" when any file has a conflict.
+func (n *Node) aggregateStatus() string {
+	var index, workTree []byte
+	untracked, conflicted := true, false
+	n.walkFiles(func(file *Node) {
+		if len(file.status) < 2 {
+			return
+		}
+		x, y := file.status[0], file.status[1]
+		if file.status != "??" {
+			untracked = false
+		}
+		if isConflicted(file.status) {
+			conflicted = true
+		}
+		if x != ' ' && x != '?' {
+			index = append(index, x)
+		}
+		if y != ' ' {
+			workTree = append(workTree, y)
+		}
+	})
+	switch {
+	case conflicted:
+		return "UU"
+	case untracked:
+		return "??"
+	}
+	combine := func(changes []byte) byte {
+		if len(changes) == 0 {
+			return ' '
+		}
+		for _, change := range changes {
+			if change != changes[0] {
+				return 'M'
+			}
+		}
+		return changes[0]
+	}
+	return string([]byte{combine(index), combine(workTree)})
+}
+
+// stagingState tells how much of the changes below a directory is staged.
+type stagingState int
+
+const (
+	stagingNone stagingState = iota
+	stagingPartial
+	stagingFull
+)
+
+// stagingState returns whether none, some or all of the changes of the files
+// below a node are staged. Untracked files count as unstaged changes.
+func (n *Node) stagingState() stagingState {
+	staged, unstaged := false, false
+	n.walkFiles(func(file *Node) {
+		if len(file.status) < 2 {
+			return
+		}
+		if file.status[0] != ' ' && file.status[0] != '?' {
+			staged = true
+		}
+		if file.status[1] != ' ' {
+			unstaged = true
+		}
+	})
+	switch {
+	case staged && !unstaged:
+		return stagingFull
+	case staged:
+		return stagingPartial
+	}
+	return stagingNone
+}
+
+// stageIcon returns the tri-state indicator of a directory.
+func (n *Node) stageIcon() string {
+	switch n.stagingState() {
+	case stagingFull:
+		return dirStagedIcon
+	case stagingPartial:
+		return dirPartlyStagedIcon
+	}
+	return dirUnstagedIcon
+}
+
+// find returns the node with path, or nil.
+func (n *Node) find(path string) *Node {
+	if n.path == path {
+		return n
+	}
+	for _, child := range n.children {
+		if child.path == path || strings.HasPrefix(path, child.path+"/") {
+			if found := child.find(path); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// walkFiles calls fn for every file below a node.
+func (n *Node) walkFiles(fn func(file *Node)) {
+	if len(n.children) == 0 {
+		fn(n)
+		return
+	}
+	for _, child := range n.children {
+		child.walkFiles(fn)
+	}
+}
+
+// directoryPaths returns the paths of all directories below a node.
+func (n *Node) directoryPaths() []string {
+	var paths []string
+	for _, child := range n.children {
+		if len(child.children) > 0 {
+			paths = append(paths, child.path)
+			paths = append(paths, child.directoryPaths()...)
+		}
+	}
+	return paths
 }
 
 // findChild searches for an immediate child node by name.
@@ -130,22 +486,28 @@ func (n *Node) compact() {
 }
 
 // renderRecursive performs a depth-first traversal of the tree to generate
-// raw, tab-delimited strings for the view to parse and style.
-func (n *Node) renderRecursive(prefix string, theme Theme) []string {
+// raw, tab-delimited strings for the view to parse and style: the prefix,
+// status, display name, path, the staged and unstaged line counts and, for
+// directories, the aggregated status of their files when collapsed and
+// whether they are fully, partly or not staged.
+func (n *Node) renderRecursive(prefix string, theme Theme, collapsed map[string]bool) []string {
 	var lines []string
 	for _, child := range n.children {
 		newPrefix := prefix + theme.Tree.Prefix
 
 		if len(child.children) > 0 { // It's a directory
-			displayName := dirExpandedIcon + child.name
-			lines = append(lines, fmt.Sprintf("%s\t\t%s\t%s", prefix, displayName, child.path))
-			lines = append(lines, child.renderRecursive(newPrefix, theme)...)
+			if collapsed[child.path] {
+				lines = append(lines, fmt.Sprintf("%s\t\t%s\t%s\t%s\t%s\t%s", prefix, dirCollapsedIcon+child.name, child.path, child.counts.columns(), child.aggregateStatus(), child.stageIcon()))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s\t\t%s\t%s\t%s\t\t%s", prefix, dirExpandedIcon+child.name, child.path, child.counts.columns(), child.stageIcon()))
+			lines = append(lines, child.renderRecursive(newPrefix, theme, collapsed)...)
 		} else { // It's a file.
 			displayName := child.name
 			if child.isRenamed {
 				displayName = child.path
 			}
-			lines = append(lines, fmt.Sprintf("%s\t%s\t%s\t%s", prefix, child.status, displayName, child.path))
+			lines = append(lines, fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t\t", prefix, child.status, displayName, child.path, child.counts.columns()))
 		}
 	}
 	return lines
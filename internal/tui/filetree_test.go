@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gitxtui/gitx/internal/git"
+)
+
+func TestBuildTree(t *testing.T) {
+	status := strings.Join([]string{
+		"MM src/main.go",
+		" M src/util.go",
+		"?? src/assets/",
+		"?? notes.txt",
+	}, "\n")
+	root := BuildTree(status)
+	root.SetLineCounts(
+		[]git.FileStat{{Path: "src/main.go", Added: 3, Deleted: 1}},
+		[]git.FileStat{{Path: "src/main.go", Added: 1}, {Path: "src/util.go", Added: 2, Deleted: 5}},
+	)
+	var got []string
+	for _, line := range root.Render(Themes[ThemeNames()[0]], nil) {
+		parts := strings.Split(line, "\t")
+		got = append(got, strings.Join(parts[1:], "|"))
+	}
+	want := []string{
+		"|4 changed files|.|+3 -1|+3 -5||" + dirPartlyStagedIcon,
+		"|" + dirExpandedIcon + "src|src|+3 -1|+3 -5||" + dirPartlyStagedIcon,
+		"??|assets/|src/assets/||||",
+		"MM|main.go|src/main.go|+3 -1|+1 -0||",
+		" M|util.go|src/util.go||+2 -5||",
+		"??|notes.txt|notes.txt||||",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildTree() rendered\n%q\nwant\n%q", got, want)
+	}
+
+	if lines := BuildTree("").Render(Themes[ThemeNames()[0]], nil); len(lines) != 0 {
+		t.Errorf("a clean tree should render no lines, got %q", lines)
+	}
+}
+
+func TestNode_AggregateStatus(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		want     string
+	}{
+		{[]string{"M ", "M "}, "M "},
+		{[]string{"A ", " M"}, "AM"},
+		{[]string{"A ", "M "}, "M "},
+		{[]string{"??", "??"}, "??"},
+		{[]string{" M", "??"}, " M"},
+		{[]string{"M ", "UU"}, "UU"},
+	}
+	for _, tt := range tests {
+		var lines []string
+		for i, status := range tt.statuses {
+			lines = append(lines, status+" dir/file"+string(rune('a'+i)))
+		}
+		dir := BuildTree(strings.Join(lines, "\n")).children[0]
+		if got := dir.aggregateStatus(); got != tt.want {
+			t.Errorf("aggregateStatus(%q) = %q, want %q", tt.statuses, got, tt.want)
+		}
+	}
+}
+
+func TestNode_StagingState(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		want     stagingState
+	}{
+		{[]string{"M ", "A "}, stagingFull},
+		{[]string{"M ", " M"}, stagingPartial},
+		{[]string{"MM"}, stagingPartial},
+		{[]string{"M ", "??"}, stagingPartial},
+		{[]string{" M", "??"}, stagingNone},
+	}
+	for _, tt := range tests {
+		var lines []string
+		for i, status := range tt.statuses {
+			lines = append(lines, status+" dir/file"+string(rune('a'+i)))
+		}
+		root := BuildTree(strings.Join(lines, "\n"))
+		if got := root.find("dir").stagingState(); got != tt.want {
+			t.Errorf("stagingState(%q) = %d, want %d", tt.statuses, got, tt.want)
+		}
+	}
+
+	root := BuildTree("M  a/b/c.go\n?? a/new/")
+	for _, path := range []string{".", "a", "a/b/c.go", "a/new/"} {
+		if node := root.find(path); node == nil || node.path != path {
+			t.Errorf("find(%q) = %+v", path, node)
+		}
+	}
+	if root.find("a/b/missing") != nil {
+		t.Error("find() of a missing path should return nil")
+	}
+}
+
+func TestModel_CollapseDirectories(t *testing.T) {
+	m := initialModel()
+	m.fileTree = BuildTree(strings.Join([]string{"M  src/a/one.go", "M  src/b/two.go", "?? notes.txt"}, "\n"))
+	m.renderFileTree("src/a/one.go", "")
+	paths := func() []string {
+		var paths []string
+		for _, line := range m.panels[FilesPanel].lines {
+			paths = append(paths, strings.Split(line, "\t")[3])
+		}
+		return paths
+	}
+	key := func(k string) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		if k == "enter" {
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		m.handleFilesPanelKeys(msg)
+	}
+
+	m.panels[FilesPanel].cursor = 2 // src/a
+	key("enter")
+	want := []string{".", "src", "src/a", "src/b", "src/b/two.go", "notes.txt"}
+	if !reflect.DeepEqual(paths(), want) {
+		t.Fatalf("after collapsing src/a got %q, want %q", paths(), want)
+	}
+	if status := strings.Split(m.panels[FilesPanel].lines[2], "\t")[6]; status != "M " {
+		t.Errorf("a collapsed directory should show the status of its files, got %q", status)
+	}
+
+	// The collapsed state is kept by path when the files are refreshed.
+	m.fileTree = BuildTree(strings.Join([]string{"M  src/a/one.go", " M src/a/three.go", "M  src/b/two.go"}, "\n"))
+	m.renderFileTree("src/a", "")
+	if got := paths(); len(got) != 5 || m.panels[FilesPanel].cursor != 2 {
+		t.Errorf("after a refresh got %q with the cursor on %d", got, m.panels[FilesPanel].cursor)
+	}
+
+	m.panels[FilesPanel].cursor = 4 // src/b/two.go
+	key("-")
+	if want := []string{".", "src"}; !reflect.DeepEqual(paths(), want) || m.panels[FilesPanel].cursor != 1 {
+		t.Errorf("after collapsing all got %q with the cursor on %d", paths(), m.panels[FilesPanel].cursor)
+	}
+	key("=")
+	if len(paths()) != 7 || m.panels[FilesPanel].cursor != 1 {
+		t.Errorf("after expanding all got %q with the cursor on %d", paths(), m.panels[FilesPanel].cursor)
+	}
+}
+
+func TestModel_FileViews(t *testing.T) {
+	m := initialModel()
+	m.fileTree = BuildTree(strings.Join([]string{"MM src/main.go", "A  src/new.go", "UU go.mod", "?? notes.txt"}, "\n"))
+	m.fileTree.SetLineCounts(
+		[]git.FileStat{{Path: "src/main.go", Added: 1}},
+		[]git.FileStat{{Path: "src/main.go", Deleted: 2}},
+	)
+	rows := func() []string {
+		var rows []string
+		for _, line := range m.panels[FilesPanel].lines {
+			parts := strings.Split(line, "\t")
+			rows = append(rows, strings.Join(parts[1:6], "|"))
+		}
+		return rows
+	}
+
+	m.fileView = fileViewFlat
+	m.renderFileTree("src/main.go", "MM")
+	want := []string{
+		"|4 changed files|.|+1 -0|+0 -2",
+		"UU|go.mod|go.mod||",
+		"??|notes.txt|notes.txt||",
+		"MM|src/main.go|src/main.go|+1 -0|+0 -2",
+		"A |src/new.go|src/new.go||",
+	}
+	if !reflect.DeepEqual(rows(), want) || m.panels[FilesPanel].cursor != 3 {
+		t.Errorf("flat view rendered\n%q\nwith the cursor on %d, want\n%q", rows(), m.panels[FilesPanel].cursor, want)
+	}
+
+	m.fileView = fileViewGrouped
+	m.renderFileTree("src/main.go", " M")
+	want = []string{
+		"|4 changed files|.|+1 -0|+0 -2",
+		"|Conflicted (1)|||",
+		"UU|go.mod|go.mod||",
+		"|Staged (2)|||",
+		"M |src/main.go|src/main.go|+1 -0|",
+		"A |src/new.go|src/new.go||",
+		"|Unstaged (1)|||",
+		" M|src/main.go|src/main.go||+0 -2",
+		"|Untracked (1)|||",
+		"??|notes.txt|notes.txt||",
+	}
+	if !reflect.DeepEqual(rows(), want) || m.panels[FilesPanel].cursor != 7 {
+		t.Errorf("grouped view rendered\n%q\nwith the cursor on %d, want\n%q", rows(), m.panels[FilesPanel].cursor, want)
+	}
+
+	// Each section's row discards only what it shows.
+	discard := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")}
+	m.panels[FilesPanel].cursor = 4
+	m.handleFilesPanelKeys(discard)
+	if m.mode != modeNormal {
+		t.Errorf("discarding the Staged row should be refused, got %q", m.confirmMessage)
+	}
+	m.panels[FilesPanel].cursor = 7
+	m.handleFilesPanelKeys(discard)
+	if m.mode != modeConfirm || m.confirmMessage != "Discard changes to src/main.go?" {
+		t.Errorf("unexpected confirmation for the Unstaged row: %q", m.confirmMessage)
+	}
+	m.mode = modeNormal
+	m.panels[FilesPanel].cursor = 7
+
+	m.handleFilesPanelKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("`")})
+	if selected := m.panels[FilesPanel].lines[m.panels[FilesPanel].cursor]; m.fileView != fileViewTree || !strings.Contains(selected, "\tsrc/main.go\t") {
+		t.Errorf("switching views should go back to the tree and keep the selection, got view %d", m.fileView)
+	}
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// filterField is a part of a list panel line that the filter matches.
+type filterField struct {
+	text string
+	// shift moves positions in text to positions in the shown column. It is
+	// negative when the column shows only the end of text, like the name of
+	// a file in the tree view.
+	shift int
+}
+
+// filterFields returns the parts of a list panel line that the filter
+// matches, one per column that can be highlighted: the path of a file, the
+// name of a branch, the SHA, author and subject of a commit, or the name and
+// message of a stash. Lines that cannot be matched, such as graph-only
+// commit lines and the Files summary row, return nil.
+func filterFields(panel Panel, line string) []filterField {
+	parts := strings.Split(line, "\t")
+	switch panel {
+	case FilesPanel:
+		if len(parts) < 4 || parts[3] == "" || parts[3] == "." {
+			return nil
+		}
+		path, name := parts[3], parts[2]
+		return []filterField{{text: path, shift: utf8.RuneCountInString(name) - utf8.RuneCountInString(path)}}
+	case BranchesPanel:
+		if len(parts) < 2 {
+			return nil
+		}
+		name := strings.TrimPrefix(parts[1], "(*) → ")
+		return []filterField{{text: name, shift: utf8.RuneCountInString(parts[1]) - utf8.RuneCountInString(name)}}
+	case CommitsPanel:
+		if len(parts) < 4 {
+			return nil
+		}
+		return []filterField{{text: parts[1]}, {text: parts[2]}, {text: parts[3]}}
+	case StashPanel:
+		if len(parts) < 2 {
+			return nil
+		}
+		return []filterField{{text: parts[0]}, {text: parts[1]}}
+	}
+	return nil
+}
+
+// matchLine fuzzy-matches filter against the fields of a line. It returns
+// the matched rune positions in each column of the line.
+func matchLine(panel Panel, line, filter string) (highlights [][]int, ok bool) {
+	fields := filterFields(panel, line)
+	if len(fields) == 0 {
+		return nil, false
+	}
+	texts := make([]string, len(fields))
+	for i, field := range fields {
+		texts[i] = field.text
+	}
+	_, positions, ok := fuzzyScore(filter, strings.Join(texts, " "))
+	if !ok {
+		return nil, false
+	}
+
+	highlights = make([][]int, len(fields))
+	field, start := 0, 0
+	for _, pos := range positions {
+		for pos >= start+utf8.RuneCountInString(texts[field])+1 {
+			start += utf8.RuneCountInString(texts[field]) + 1
+			field++
+		}
+		if shown := pos - start + fields[field].shift; shown >= 0 {
+			highlights[field] = append(highlights[field], shown)
+		}
+	}
+	return highlights, true
+}
+
+// narrow keeps every line of a list panel and returns the lines that match
+// its filter, remembering where they matched.
+func (p *panel) narrow(id Panel, lines []string) []string {
+	p.highlights = nil
+	if p.filter == "" {
+		p.allLines = nil
+		return lines
+	}
+	p.allLines = lines
+	var matched []string
+	for _, line := range lines {
+		if highlights, ok := matchLine(id, line, p.filter); ok {
+			matched = append(matched, line)
+			p.highlights = append(p.highlights, highlights)
+		}
+	}
+	return matched
+}
+
+// setFilter narrows a list panel to the lines matching filter, or shows all
+// of them again when it is empty, keeping the cursor on the selected line
+// if it is still shown.
+func (p *panel) setFilter(id Panel, filter string) {
+	lines := p.lines
+	if p.filter != "" {
+		lines = p.allLines
+	}
+	var selected string
+	if p.cursor < len(p.lines) {
+		selected = p.lines[p.cursor]
+	}
+
+	p.filter = filter
+	p.lines = p.narrow(id, lines)
+	p.viewport.SetContent(strings.Join(p.lines, "\n"))
+	p.cursor = max(slices.Index(p.lines, selected), 0)
+	if p.cursor < p.viewport.YOffset {
+		p.viewport.SetYOffset(p.cursor)
+	}
+	if p.cursor >= p.viewport.YOffset+p.viewport.Height {
+		p.viewport.SetYOffset(p.cursor - p.viewport.Height + 1)
+	}
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// fuzzyMatch is the result of matching a pattern against a single candidate.
+type fuzzyMatch struct {
+	index     int   // Index of the candidate in the original slice.
+	score     int   // Higher is better.
+	positions []int // Rune positions in the candidate that matched the pattern.
+}
+
+// fuzzyScore matches pattern against candidate as a case-insensitive
+// subsequence. Consecutive matches and matches at word starts score higher,
+// gaps score lower. ok is false when the pattern does not match at all.
+func fuzzyScore(pattern, candidate string) (score int, positions []int, ok bool) {
+	patternRunes := []rune(strings.ToLower(pattern))
+	if len(patternRunes) == 0 {
+		return 0, nil, true
+	}
+	candidateRunes := []rune(candidate)
+
+	p := 0
+	lastMatch := -1
+	for i, r := range candidateRunes {
+		if p == len(patternRunes) {
+			break
+		}
+		if unicode.ToLower(r) != patternRunes[p] {
+			continue
+		}
+
+		score += fuzzyMatchScore
+		if lastMatch >= 0 && i == lastMatch+1 {
+			score += fuzzyConsecutiveBonus
+		} else if lastMatch >= 0 {
+			score -= i - lastMatch - 1
+		}
+		if i == 0 || isWordBoundary(candidateRunes[i-1]) {
+			score += fuzzyWordStartBonus
+		}
+
+		positions = append(positions, i)
+		lastMatch = i
+		p++
+	}
+
+	if p < len(patternRunes) {
+		return 0, nil, false
+	}
+	return score, positions, true
+}
+
+// fuzzyFilter returns the candidates that match pattern, best matches first.
+// An empty pattern keeps every candidate in its original order.
+func fuzzyFilter(pattern string, candidates []string) []fuzzyMatch {
+	var matches []fuzzyMatch
+	for i, candidate := range candidates {
+		score, positions, ok := fuzzyScore(pattern, candidate)
+		if ok {
+			matches = append(matches, fuzzyMatch{index: i, score: score, positions: positions})
+		}
+	}
+	if pattern != "" {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].score > matches[j].score
+		})
+	}
+	return matches
+}
+
+// isWordBoundary reports whether r separates words in paths, names and messages.
+func isWordBoundary(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("/_-.<>@:()", r)
+}
+
+// highlightMatches renders s with the runes at positions in matchStyle and the
+// rest in baseStyle.
+func highlightMatches(s string, positions []int, baseStyle, matchStyle lipgloss.Style) string {
+	if len(positions) == 0 {
+		return baseStyle.Render(s)
+	}
+	matched := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		matched[pos] = true
+	}
+
+	var builder strings.Builder
+	var run []rune
+	runMatched := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMatched {
+			builder.WriteString(matchStyle.Render(string(run)))
+		} else {
+			builder.WriteString(baseStyle.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(s) {
+		if matched[i] != runMatched {
+			flush()
+			runMatched = matched[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return builder.String()
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	testCases := []struct {
+		pattern, candidate string
+		wantOK             bool
+		wantPositions      []int
+	}{
+		{"", "anything", true, nil},
+		{"jd", "Jane Doe <jane@example.com>", true, []int{0, 5}},
+		{"JANE", "Jane Doe", true, []int{0, 1, 2, 3}},
+		{"xyz", "Jane Doe", false, nil},
+		{"mdl", "internal/tui/model.go", true, []int{13, 15, 17}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" in "+tc.candidate, func(t *testing.T) {
+			_, positions, ok := fuzzyScore(tc.pattern, tc.candidate)
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tc.wantOK)
+			}
+			if !reflect.DeepEqual(positions, tc.wantPositions) {
+				t.Errorf("positions = %v, want %v", positions, tc.wantPositions)
+			}
+		})
+	}
+}
+
+func TestFuzzyFilter_RanksWordStartsFirst(t *testing.T) {
+	candidates := []string{"demo.go", "tui/model.go", "readme.md"}
+	matches := fuzzyFilter("mo", candidates)
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(matches))
+	}
+	if got := candidates[matches[0].index]; got != "tui/model.go" {
+		t.Errorf("best match = %q, want %q", got, "tui/model.go")
+	}
+
+	all := fuzzyFilter("", candidates)
+	for i, match := range all {
+		if match.index != i {
+			t.Errorf("empty pattern should keep order, got index %d at %d", match.index, i)
+		}
+	}
+}
+
+func TestMatchLine(t *testing.T) {
+	testCases := []struct {
+		panel          Panel
+		line, filter   string
+		wantOK         bool
+		wantHighlights [][]int
+	}{
+		// Tree rows match the whole path but show only the name.
+		{FilesPanel, "  \tM \tmodel.go\ttui/model.go\t\t\t\t", "tmo", true, [][]int{{0, 1}}},
+		{FilesPanel, "\t\t2 changed files\t.\t\t\t\t●", "ch", false, nil},
+		{BranchesPanel, "2 days ago\t(*) → main", "mn", true, [][]int{{6, 9}}},
+		{CommitsPanel, "*\tabc123\tJD\tFix parser", "jdfix", true, [][]int{nil, {0, 1}, {0, 1, 2}}},
+		{CommitsPanel, "|\\", "", false, nil},
+		{StashPanel, "stash@{0}\tmain: WIP", "wip", true, [][]int{nil, {6, 7, 8}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filter+" in "+tc.line, func(t *testing.T) {
+			highlights, ok := matchLine(tc.panel, tc.line, tc.filter)
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tc.wantOK)
+			}
+			if !reflect.DeepEqual(highlights, tc.wantHighlights) {
+				t.Errorf("highlights = %v, want %v", highlights, tc.wantHighlights)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gitxtui/gitx/internal/git"
+)
+
+// imagePreviewMode selects how images are drawn in the Main panel.
+type imagePreviewMode string
+
+const (
+	imagePreviewAuto imagePreviewMode = "auto"
+	// imagePreviewKitty draws images with the kitty graphics protocol, using
+	// Unicode placeholders so that they scroll with the text around them.
+	imagePreviewKitty imagePreviewMode = "kitty"
+	// imagePreviewSixel draws images with sixel graphics. Sixel images are not
+	// tied to cells, so an image is only drawn while all of its rows are in
+	// view, and again whenever its last row is redrawn.
+	imagePreviewSixel imagePreviewMode = "sixel"
+	// imagePreviewBlocks draws images with half blocks in 24-bit color.
+	imagePreviewBlocks imagePreviewMode = "blocks"
+	imagePreviewOff    imagePreviewMode = "off"
+)
+
+// kittyPlaceholder is the character whose cells display a kitty image.
+const kittyPlaceholder = '\U0010EEEE'
+
+// kittyRowDiacritics are the combining marks that number the rows of kitty
+// placeholder cells, from the kitty graphics protocol specification.
+var kittyRowDiacritics = []rune{
+	'\u0305', '\u030D', '\u030E', '\u0310', '\u0312', '\u033D', '\u033E', '\u033F',
+	'\u0346', '\u034A', '\u034B', '\u034C', '\u0350', '\u0351', '\u0352', '\u0357',
+	'\u035B', '\u0363', '\u0364', '\u0365', '\u0366', '\u0367', '\u0368', '\u0369',
+}
+
+// kittyImageID numbers the images sent to the terminal. IDs are encoded in
+// the 256-color foreground of the placeholders, so they wrap at 255.
+var kittyImageID atomic.Uint32
+
+// sixelImagePattern matches a sixel image sent by sixelPreview. The number
+// of rows the image reaches above the row carrying it is captured.
+var sixelImagePattern = regexp.MustCompile(`\x1b7(?:\x1b\[(\d+)A)?\x1b\[\d+D\x1bP[^\x1b]*\x1b\\\x1b8`)
+
+// loadImagePreviewMode reads `gitx.diff.imagePreview`: "kitty", "sixel",
+// "blocks", "off" or "auto", the default, which detects what the terminal
+// supports.
+func loadImagePreviewMode(gc *git.GitCommands) imagePreviewMode {
+	if value, err := gc.GetConfigValue("gitx.diff.imagePreview"); err == nil {
+		switch mode := imagePreviewMode(value); mode {
+		case imagePreviewKitty, imagePreviewSixel, imagePreviewBlocks, imagePreviewOff:
+			return mode
+		}
+	}
+	return detectImagePreview(os.Getenv)
+}
+
+// detectImagePreview picks the best preview the terminal supports from its
+// environment variables.
+func detectImagePreview(getenv func(string) string) imagePreviewMode {
+	term := getenv("TERM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "", strings.Contains(term, "kitty"),
+		strings.Contains(term, "ghostty"), getenv("TERM_PROGRAM") == "ghostty":
+		return imagePreviewKitty
+	case strings.HasPrefix(term, "foot"), strings.HasPrefix(term, "mlterm"), strings.HasPrefix(term, "contour"),
+		strings.Contains(term, "sixel"), getenv("TERM_PROGRAM") == "WezTerm":
+		return imagePreviewSixel
+	case getenv("COLORTERM") == "truecolor", getenv("COLORTERM") == "24bit":
+		return imagePreviewBlocks
+	}
+	return imagePreviewOff
+}
+
+// renderImagePreview returns the rows drawing an image in the Main panel, or
+// nil when previews are off or the image cannot be decoded.
+func (m Model) renderImagePreview(data []byte) []string {
+	if m.imagePreview != imagePreviewKitty && m.imagePreview != imagePreviewSixel && m.imagePreview != imagePreviewBlocks {
+		return nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil
+	}
+	cols, rows := previewCells(bounds.Dx(), bounds.Dy(), m.panels[MainPanel].viewport.Width)
+	if m.imagePreview == imagePreviewKitty {
+		return kittyPreview(img, cols, rows)
+	}
+	if m.imagePreview == imagePreviewSixel {
+		// Without the size of a cell the image could spill out of its rows.
+		if cellWidth, cellHeight := terminalCellSize(); cellWidth > 0 && cellHeight > 0 {
+			return sixelPreview(img, cols, rows, cellWidth, cellHeight)
+		}
+	}
+	return blockPreview(img, cols, rows)
+}
+
+// previewCells returns the number of terminal cells an image is drawn in,
+// keeping its aspect ratio with cells about twice as tall as they are wide.
+func previewCells(width, height, maxCols int) (cols, rows int) {
+	cols = max(1, min(width, maxCols, imagePreviewMaxCols))
+	rows = max(1, (cols*height+2*width-1)/(2*width))
+	if rows > imagePreviewMaxRows {
+		rows = imagePreviewMaxRows
+		cols = max(1, rows*2*width/height)
+	}
+	return cols, rows
+}
+
+// kittyPreview transmits img to the terminal and returns rows of placeholder
+// cells that display it. The image is sent with the first row; the terminal
+// keeps it after that row scrolls out of view.
+func kittyPreview(img image.Image, cols, rows int) []string {
+	var encoded bytes.Buffer
+	// The terminal fits the image to the cells, so there is no point in
+	// sending more pixels than they can show.
+	bounds := img.Bounds()
+	scaled := scaleImage(img, min(bounds.Dx(), cols*imagePreviewCellPixels), min(bounds.Dy(), rows*2*imagePreviewCellPixels))
+	if err := png.Encode(&encoded, scaled); err != nil {
+		return nil
+	}
+	payload := base64.StdEncoding.EncodeToString(encoded.Bytes())
+	id := kittyImageID.Add(1)%255 + 1
+
+	var transmit strings.Builder
+	for offset := 0; offset < len(payload); offset += 4096 {
+		more := 0
+		if offset+4096 < len(payload) {
+			more = 1
+		}
+		chunk := payload[offset:min(offset+4096, len(payload))]
+		if offset == 0 {
+			fmt.Fprintf(&transmit, "\x1b_Ga=T,U=1,f=100,q=2,i=%d,c=%d,r=%d,m=%d;%s\x1b\\", id, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&transmit, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+
+	lines := make([]string, rows)
+	for row := range lines {
+		// The first cell of a row carries its row and column numbers; the
+		// following cells continue from it.
+		line := fmt.Sprintf("\x1b[38;5;%dm%c%c%c%s\x1b[39m", id, kittyPlaceholder, kittyRowDiacritics[row], kittyRowDiacritics[0],
+			strings.Repeat(string(kittyPlaceholder), cols-1))
+		if row == 0 {
+			line = transmit.String() + line
+		}
+		lines[row] = line
+	}
+	return lines
+}
+
+// sixelPreview returns rows of blank cells with img drawn over them in sixel
+// graphics, fitted to cells of cellWidth by cellHeight pixels. The image is
+// sent with the last row and drawn from the first one: a terminal erases
+// the pixels under cells it writes, so the rows must be written before it.
+func sixelPreview(img image.Image, cols, rows, cellWidth, cellHeight int) []string {
+	bounds := img.Bounds()
+	width, height := cols*cellWidth, rows*cellHeight
+	if bounds.Dx()*height > bounds.Dy()*width {
+		height = max(1, bounds.Dy()*width/bounds.Dx())
+	} else {
+		width = max(1, bounds.Dx()*height/bounds.Dy())
+	}
+
+	blank := strings.Repeat(" ", cols)
+	lines := make([]string, rows)
+	for row := range lines {
+		lines[row] = blank
+	}
+	var b strings.Builder
+	// Save the cursor, which drawing the image moves, and go back to the
+	// first cell of the first row.
+	b.WriteString(blank + "\x1b7")
+	if rows > 1 {
+		fmt.Fprintf(&b, "\x1b[%dA", rows-1)
+	}
+	fmt.Fprintf(&b, "\x1b[%dD", cols)
+	writeSixel(&b, scaleImage(img, width, height))
+	b.WriteString("\x1b8")
+	lines[rows-1] = b.String()
+	return lines
+}
+
+// writeSixel encodes img in sixel graphics with the colors of a 6×6×6 cube.
+// Transparent pixels are not painted.
+func writeSixel(b *strings.Builder, img *image.NRGBA) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	// The second parameter keeps pixels that are not painted transparent.
+	fmt.Fprintf(b, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+
+	var defined [216]bool
+	bands := make([][]byte, len(defined))
+	for top := 0; top < height; top += 6 {
+		// Each band of six pixel rows is painted once per color, with a bit
+		// per row in each column.
+		var colors []int
+		for y := top; y < min(top+6, height); y++ {
+			for x := range width {
+				c := img.NRGBAAt(x, y)
+				if c.A < 128 {
+					continue
+				}
+				index := (int(c.R)+25)/51*36 + (int(c.G)+25)/51*6 + (int(c.B)+25)/51
+				if bands[index] == nil {
+					bands[index] = make([]byte, width)
+					colors = append(colors, index)
+				}
+				bands[index][x] |= 1 << (y - top)
+			}
+		}
+		for i, index := range colors {
+			if i > 0 {
+				b.WriteByte('$')
+			}
+			if defined[index] {
+				fmt.Fprintf(b, "#%d", index)
+			} else {
+				fmt.Fprintf(b, "#%d;2;%d;%d;%d", index, index/36*20, index/6%6*20, index%6*20)
+				defined[index] = true
+			}
+			writeSixelRuns(b, bands[index])
+			bands[index] = nil
+		}
+		b.WriteByte('-')
+	}
+	b.WriteString("\x1b\\")
+}
+
+// writeSixelRuns writes the columns of a band in one color, repeating runs
+// of equal columns with "!".
+func writeSixelRuns(b *strings.Builder, columns []byte) {
+	for len(columns) > 0 && columns[len(columns)-1] == 0 {
+		columns = columns[:len(columns)-1]
+	}
+	for x := 0; x < len(columns); {
+		run := 1
+		for x+run < len(columns) && columns[x+run] == columns[x] {
+			run++
+		}
+		if sixel := '?' + columns[x]; run > 3 {
+			fmt.Fprintf(b, "!%d%c", run, sixel)
+		} else {
+			b.WriteString(strings.Repeat(string(sixel), run))
+		}
+		x += run
+	}
+}
+
+// clipSixelImages removes the sixel images from the visible lines of a
+// panel whose first rows are scrolled out of view, which would otherwise be
+// drawn over what is above the panel.
+func clipSixelImages(lines []string) []string {
+	for i, line := range lines {
+		if !strings.Contains(line, "\x1bP") {
+			continue
+		}
+		lines[i] = sixelImagePattern.ReplaceAllStringFunc(line, func(image string) string {
+			above, _ := strconv.Atoi(sixelImagePattern.FindStringSubmatch(image)[1])
+			if above > i {
+				return ""
+			}
+			return image
+		})
+	}
+	return lines
+}
+
+// blockPreview draws img with "▀" cells whose foreground is the upper pixel
+// and background the lower one. Transparent pixels show the panel behind.
+func blockPreview(img image.Image, cols, rows int) []string {
+	scaled := scaleImage(img, cols, rows*2)
+	lines := make([]string, rows)
+	for row := range lines {
+		var b strings.Builder
+		for col := range cols {
+			top := color.NRGBAModel.Convert(scaled.At(col, row*2)).(color.NRGBA)
+			bottom := color.NRGBAModel.Convert(scaled.At(col, row*2+1)).(color.NRGBA)
+			b.WriteString("\x1b[0m")
+			switch {
+			case top.A < 128 && bottom.A < 128:
+				b.WriteString(" ")
+			case top.A < 128:
+				fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dm▄", bottom.R, bottom.G, bottom.B)
+			case bottom.A < 128:
+				fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dm▀", top.R, top.G, top.B)
+			default:
+				fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			}
+		}
+		b.WriteString("\x1b[0m")
+		lines[row] = b.String()
+	}
+	return lines
+}
+
+// scaleImage resizes img to width by height pixels with nearest-neighbor
+// sampling.
+func scaleImage(img image.Image, width, height int) *image.NRGBA {
+	bounds := img.Bounds()
+	scaled := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			sx := bounds.Min.X + (2*x+1)*bounds.Dx()/(2*width)
+			sy := bounds.Min.Y + (2*y+1)*bounds.Dy()/(2*height)
+			scaled.Set(x, y, img.At(sx, sy))
+		}
+	}
+	return scaled
+}
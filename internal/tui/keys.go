@@ -1,6 +1,9 @@
 package tui
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/gitxtui/gitx/internal/git"
+)
 
 // KeyMap defines the keybindings for the application.
 type KeyMap struct {
@@ -25,24 +28,73 @@ type KeyMap struct {
 	Up         key.Binding
 	Down       key.Binding
 
+	// keybindings for selecting several lines of a list panel
+	ToggleSelection key.Binding
+	RangeSelection  key.Binding
+	Filter          key.Binding
+
+	// Keybindings for MainPanel
+	ToggleSplitDiff         key.Binding
+	ToggleIgnoreAllSpace    key.Binding
+	ToggleIgnoreSpaceChange key.Binding
+	ToggleIgnoreBlankLines  key.Binding
+	IncreaseContext         key.Binding
+	DecreaseContext         key.Binding
+	CycleRenameThreshold    key.Binding
+	CycleDiffAlgorithm      key.Binding
+	OpenComparisonFile      key.Binding
+	ToggleThreeDot          key.Binding
+	LoadMoreRows            key.Binding
+
+	// Keybindings for StatusPanel while an operation is in progress
+	ContinueOperation key.Binding
+	SkipOperation     key.Binding
+	AbortOperation    key.Binding
+
 	// Keybindings for FilesPanel
-	StageItem key.Binding
-	StageAll  key.Binding
-	Discard   key.Binding
-	Stash     key.Binding
-	StashAll  key.Binding
-	Commit    key.Binding
+	StageItem   key.Binding
+	StageAll    key.Binding
+	Discard     key.Binding
+	Stash       key.Binding
+	StashAll    key.Binding
+	Commit      key.Binding
+	AmendNoEdit key.Binding
+	Absorb      key.Binding
+
+	ToggleDirectory        key.Binding
+	CollapseAllDirectories key.Binding
+	ExpandAllDirectories   key.Binding
+	CycleFileView          key.Binding
+
+	// Keybindings for the commit pop-up
+	AddTrailer key.Binding
 
 	// Keybindings for BranchesPanel
-	Checkout     key.Binding
-	NewBranch    key.Binding
-	DeleteBranch key.Binding
-	RenameBranch key.Binding
+	Checkout        key.Binding
+	NewBranch       key.Binding
+	DeleteBranch    key.Binding
+	RenameBranch    key.Binding
+	MergeBranch     key.Binding
+	RebaseBranch    key.Binding
+	PredictMerge    key.Binding
+	DiffBranch      key.Binding
+	CompareWithBase key.Binding
 
 	// Keybindings for CommitsPanel
-	AmendCommit   key.Binding
-	Revert        key.Binding
-	ResetToCommit key.Binding
+	AmendCommit    key.Binding
+	RewordCommit   key.Binding
+	CreateFixup    key.Binding
+	Autosquash     key.Binding
+	SquashCommit   key.Binding
+	DropCommit     key.Binding
+	MoveCommitUp   key.Binding
+	MoveCommitDown key.Binding
+	SplitCommit    key.Binding
+	MarkCommit     key.Binding
+	Revert         key.Binding
+	CherryPick     key.Binding
+	ResetToCommit  key.Binding
+	DiffMarked     key.Binding
 
 	// Keybindings for StashPanel
 	StashApply key.Binding
@@ -66,22 +118,44 @@ func (k KeyMap) FullHelp() []HelpSection {
 				k.FocusNext, k.FocusPrev, k.FocusZero, k.FocusOne,
 				k.FocusTwo, k.FocusThree, k.FocusFour, k.FocusFive,
 				k.FocusSix, k.Up, k.Down,
+				k.ToggleSelection, k.RangeSelection, k.Filter,
 			},
 		},
+		{
+			Title: "Main",
+			Bindings: []key.Binding{
+				k.ToggleSplitDiff, k.ToggleIgnoreAllSpace, k.ToggleIgnoreSpaceChange, k.ToggleIgnoreBlankLines,
+				k.IncreaseContext, k.DecreaseContext, k.CycleRenameThreshold, k.CycleDiffAlgorithm,
+				k.OpenComparisonFile, k.ToggleThreeDot, k.LoadMoreRows,
+			},
+		},
+		{
+			Title:    "Status (during merge, rebase, ...)",
+			Bindings: []key.Binding{k.ContinueOperation, k.SkipOperation, k.AbortOperation},
+		},
 		{
 			Title: "Files",
 			Bindings: []key.Binding{
-				k.Commit, k.Stash, k.StashAll, k.StageItem,
-				k.StageAll, k.Discard,
+				k.Commit, k.AmendNoEdit, k.Absorb, k.Stash, k.StashAll,
+				k.StageItem, k.StageAll, k.Discard,
+				k.ToggleDirectory, k.CollapseAllDirectories, k.ExpandAllDirectories, k.CycleFileView,
 			},
 		},
+		{
+			Title:    "Commit Message",
+			Bindings: []key.Binding{k.AddTrailer},
+		},
 		{
 			Title:    "Branches",
-			Bindings: []key.Binding{k.Checkout, k.NewBranch, k.DeleteBranch, k.RenameBranch},
+			Bindings: []key.Binding{k.Checkout, k.NewBranch, k.DeleteBranch, k.RenameBranch, k.MergeBranch, k.RebaseBranch, k.PredictMerge, k.DiffBranch, k.CompareWithBase},
 		},
 		{
-			Title:    "Commits",
-			Bindings: []key.Binding{k.AmendCommit, k.Revert, k.ResetToCommit},
+			Title: "Commits",
+			Bindings: []key.Binding{
+				k.AmendCommit, k.RewordCommit, k.CreateFixup, k.Autosquash,
+				k.SquashCommit, k.DropCommit, k.MoveCommitUp, k.MoveCommitDown, k.SplitCommit,
+				k.MarkCommit, k.Revert, k.CherryPick, k.ResetToCommit, k.DiffMarked,
+			},
 		},
 		{
 			Title:    "Stash",
@@ -104,6 +178,28 @@ func (k KeyMap) HelpViewHelp() []key.Binding {
 	return []key.Binding{k.ToggleHelp, k.Escape, k.Quit}
 }
 
+// MainPanelHelp returns a slice of key.Binding for the Main Panel help bar.
+func (k KeyMap) MainPanelHelp() []key.Binding {
+	help := []key.Binding{k.ToggleSplitDiff, k.ToggleIgnoreAllSpace, k.IncreaseContext, k.DecreaseContext}
+	return append(help, k.ShortHelp()...)
+}
+
+// StatusPanelHelp returns a slice of key.Binding for the Status Panel help
+// bar, including the actions available for the in-progress operation.
+func (k KeyMap) StatusPanelHelp(state git.RepoState) []key.Binding {
+	var help []key.Binding
+	if state.CanContinue() {
+		help = append(help, k.ContinueOperation)
+	}
+	if state.CanSkip() {
+		help = append(help, k.SkipOperation)
+	}
+	if state.InProgress() {
+		help = append(help, k.AbortOperation)
+	}
+	return append(help, k.ShortHelp()...)
+}
+
 // FilesPanelHelp returns a slice of key.Binding containing help for keybindings related to Files Panel.
 func (k KeyMap) FilesPanelHelp() []key.Binding {
 	help := []key.Binding{k.Commit, k.Stash, k.Discard, k.StageItem}
@@ -112,13 +208,13 @@ func (k KeyMap) FilesPanelHelp() []key.Binding {
 
 // BranchesPanelHelp returns a slice of key.Binding for the Branches Panel help bar.
 func (k KeyMap) BranchesPanelHelp() []key.Binding {
-	help := []key.Binding{k.Checkout, k.NewBranch, k.DeleteBranch}
+	help := []key.Binding{k.Checkout, k.NewBranch, k.DeleteBranch, k.MergeBranch, k.RebaseBranch}
 	return append(help, k.ShortHelp()...)
 }
 
 // CommitsPanelHelp returns a slice of key.Binding for the Commits Panel help bar.
 func (k KeyMap) CommitsPanelHelp() []key.Binding {
-	help := []key.Binding{k.AmendCommit, k.Revert, k.ResetToCommit}
+	help := []key.Binding{k.AmendCommit, k.RewordCommit, k.Revert, k.ResetToCommit}
 	return append(help, k.ShortHelp()...)
 }
 
@@ -196,6 +292,78 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("j", "down"),
 			key.WithHelp("j/↓", "down"),
 		),
+		ToggleSelection: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "Select/unselect line"),
+		),
+		RangeSelection: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "Start/keep range selection"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "Filter list (Esc restores it)"),
+		),
+
+		// MainPanel
+		ToggleSplitDiff: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "Toggle side-by-side diff"),
+		),
+		ToggleIgnoreAllSpace: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "Ignore all whitespace (-w)"),
+		),
+		ToggleIgnoreSpaceChange: key.NewBinding(
+			key.WithKeys("W"),
+			key.WithHelp("W", "Ignore whitespace changes (-b)"),
+		),
+		ToggleIgnoreBlankLines: key.NewBinding(
+			key.WithKeys("B"),
+			key.WithHelp("B", "Ignore blank lines"),
+		),
+		IncreaseContext: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "More context lines"),
+		),
+		DecreaseContext: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "Fewer context lines"),
+		),
+		CycleRenameThreshold: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "Cycle rename threshold"),
+		),
+		CycleDiffAlgorithm: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "Cycle diff algorithm"),
+		),
+		OpenComparisonFile: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "Open file of comparison"),
+		),
+		ToggleThreeDot: key.NewBinding(
+			key.WithKeys("."),
+			key.WithHelp(".", "Toggle two-dot/three-dot comparison"),
+		),
+		LoadMoreRows: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "Load more of a long diff"),
+		),
+
+		// StatusPanel
+		ContinueOperation: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "Continue"),
+		),
+		SkipOperation: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "Skip"),
+		),
+		AbortOperation: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "Abort"),
+		),
 
 		// FilesPanel
 		StageItem: key.NewBinding(
@@ -223,6 +391,37 @@ func DefaultKeyMap() KeyMap {
 			key.WithHelp("c", "Commit"),
 		),
 
+		AmendNoEdit: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "Amend last commit (keep message)"),
+		),
+		Absorb: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "Absorb staged hunks into fixups"),
+		),
+
+		ToggleDirectory: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "Collapse/expand directory"),
+		),
+		CollapseAllDirectories: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "Collapse all directories"),
+		),
+		ExpandAllDirectories: key.NewBinding(
+			key.WithKeys("="),
+			key.WithHelp("=", "Expand all directories"),
+		),
+		CycleFileView: key.NewBinding(
+			key.WithKeys("`"),
+			key.WithHelp("`", "Switch tree/flat/grouped view"),
+		),
+
+		AddTrailer: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("<c+r>", "Add Trailer"),
+		),
+
 		Checkout: key.NewBinding(
 			key.WithKeys("enter"),
 			key.WithHelp("enter", "Checkout"),
@@ -239,14 +438,78 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "Rename"),
 		),
+		MergeBranch: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("M", "Merge into current"),
+		),
+		RebaseBranch: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "Rebase current onto"),
+		),
+		PredictMerge: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "Toggle merge conflict preview"),
+		),
+		DiffBranch: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "Diff marked commit (or HEAD) against branch"),
+		),
+		CompareWithBase: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "Toggle comparison with base branch"),
+		),
 
 		AmendCommit: key.NewBinding(
 			key.WithKeys("A"),
-			key.WithHelp("A", "Amend"),
+			key.WithHelp("A", "Amend (edit message)"),
+		),
+		RewordCommit: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "Reword"),
+		),
+		CreateFixup: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "Create fixup commit"),
+		),
+		Autosquash: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "Squash fixups above (autosquash)"),
+		),
+		SquashCommit: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "Squash into parent"),
+		),
+		DropCommit: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "Drop commit"),
+		),
+		MoveCommitUp: key.NewBinding(
+			key.WithKeys("ctrl+k"),
+			key.WithHelp("<c+k>", "Move commit up"),
+		),
+		MoveCommitDown: key.NewBinding(
+			key.WithKeys("ctrl+j"),
+			key.WithHelp("<c+j>", "Move commit down"),
+		),
+		SplitCommit: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "Split commit"),
+		),
+		MarkCommit: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "Mark commit as range start"),
+		),
+		DiffMarked: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "Diff selected against marked commit (or HEAD)"),
 		),
 		Revert: key.NewBinding(
 			key.WithKeys("v"),
-			key.WithHelp("v", "Revert"),
+			key.WithHelp("v", "Revert (selected commits or marked range if any)"),
+		),
+		CherryPick: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "Cherry-pick onto current branch"),
 		),
 		ResetToCommit: key.NewBinding(
 			key.WithKeys("R"),
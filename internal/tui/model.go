@@ -18,8 +18,29 @@ const (
 	modeInput
 	modeConfirm
 	modeCommit
+	modeTrailer
+	modeMenu
+	modeAbsorb
+	modeFilter
 )
 
+// branchMainView selects what the Main panel shows for the selected branch.
+type branchMainView int
+
+const (
+	branchMainLog branchMainView = iota
+	branchMainMergePreview
+	branchMainBaseComparison
+)
+
+// menuItem is a single choice in the menu pop-up. The action receives the
+// live model so that it can open a follow-up pop-up.
+type menuItem struct {
+	label  string
+	detail string // Optional extra lines shown while the item is selected.
+	action func(m *Model) tea.Cmd
+}
+
 // Model represents the state of the TUI.
 type Model struct {
 	width             int
@@ -47,6 +68,25 @@ type Model struct {
 	inputCallback    func(string) tea.Cmd
 	commitCallback   func(title, description string) tea.Cmd
 	confirmCallback  func(bool) tea.Cmd
+	commitLint       commitLintRules
+	trailerPicker    trailerPicker
+	menuTitle        string
+	menuItems        []menuItem
+	menuCursor       int
+	absorbReview     absorbReview
+	commitMark       string // SHA of the commit marked as the other end of a range.
+	repoState        git.RepoState
+	branchMainView   branchMainView
+	diffSplit        bool            // Show diffs side by side in the Main panel.
+	diffOptions      git.DiffOptions // Whitespace, context, rename and algorithm settings of Main panel diffs.
+	wordDiff         wordDiffConfig
+	imagePreview     imagePreviewMode
+	mainRowLimit     int             // Rows of a diff shown before "load more"; 0 is diffMaxRows.
+	mainRows         *renderedRows   // Main panel rows that may still need syntax highlighting.
+	comparison       *diffComparison // Set while the Main panel compares two revisions.
+	fileTree         *Node           // The Files panel tree, rendered again when directories collapse.
+	collapsedDirs    map[string]bool // Paths of the collapsed directories of the Files panel.
+	fileView         fileViewMode
 }
 
 // initialModel creates the initial state of the application.
@@ -92,6 +132,8 @@ func initialModel() Model {
 		mode:              modeNormal,
 		textInput:         ti,
 		descriptionInput:  ta,
+		wordDiff:          loadWordDiffConfig(gc),
+		imagePreview:      loadImagePreviewMode(gc),
 	}
 }
 
@@ -118,6 +160,10 @@ func (m *Model) nextTheme() {
 // panelShortHelp returns a slice of key.Binding for the focused Panel.
 func (m *Model) panelShortHelp() []key.Binding {
 	switch m.focusedPanel {
+	case MainPanel:
+		return keys.MainPanelHelp()
+	case StatusPanel:
+		return keys.StatusPanelHelp(m.repoState)
 	case FilesPanel:
 		return keys.FilesPanelHelp()
 	case BranchesPanel:
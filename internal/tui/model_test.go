@@ -384,6 +384,196 @@ func TestModel_Update_FileWatcher(t *testing.T) {
 	}
 }
 
+func TestModel_MenuSelection(t *testing.T) {
+	m := initialModel()
+	var chosen string
+	m.openMenu("Pick one", []menuItem{
+		{label: "first", action: func(m *Model) tea.Cmd { chosen = "first"; return nil }},
+		{label: "second", action: func(m *Model) tea.Cmd {
+			chosen = "second"
+			m.mode = modeConfirm
+			return nil
+		}},
+	})
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updatedModel, _ = updatedModel.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if chosen != "second" {
+		t.Fatalf("expected second item to be chosen, got %q", chosen)
+	}
+	if updatedModel.(Model).mode != modeConfirm {
+		t.Error("a menu action should be able to open a follow-up pop-up")
+	}
+
+	m.openMenu("Pick one", []menuItem{{label: "first", action: func(m *Model) tea.Cmd { return nil }}})
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updatedModel.(Model).mode != modeNormal {
+		t.Error("esc should close the menu")
+	}
+}
+
+func TestModel_Selection(t *testing.T) {
+	m := initialModel()
+	m.focusedPanel = CommitsPanel
+	m.panels[CommitsPanel].lines = []string{
+		"*\taaa\tAB\tFifth",
+		"*\tbbb\tAB\tFourth",
+		"|\\",
+		"*\tccc\tAB\tThird",
+		"*\tddd\tAB\tSecond",
+		"*\teee\tAB\tFirst",
+	}
+	press := func(k string) {
+		m.handleCommitsPanelKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+	selection := func() []string { return m.panels[CommitsPanel].selection(CommitsPanel) }
+
+	press("t")
+	press("j")
+	press("V")
+	press("j")
+	press("j")
+	press("j")
+	if want := []string{"aaa", "bbb", "ccc", "ddd"}; !reflect.DeepEqual(selection(), want) {
+		t.Fatalf("got selection %q, want %q", selection(), want)
+	}
+
+	// Ending the range keeps its lines selected; the graph line is skipped.
+	press("V")
+	press("j")
+	if want := []string{"aaa", "bbb", "ccc", "ddd"}; !reflect.DeepEqual(selection(), want) {
+		t.Errorf("after ending the range got selection %q, want %q", selection(), want)
+	}
+
+	press("C")
+	if m.mode != modeConfirm || !strings.Contains(m.confirmMessage, "Cherry-pick 4 commit(s)") || !strings.Contains(m.confirmMessage, "ccc Third") {
+		t.Errorf("unexpected cherry-pick confirmation %q", m.confirmMessage)
+	}
+	m.mode = modeNormal
+
+	// A selection and a marked range together are refused.
+	m.commitMark = "eee"
+	cmd := m.handleCommitsPanelKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	if _, ok := cmd().(errMsg); !ok || m.mode != modeNormal {
+		t.Errorf("reverting with both a selection and a marked commit should fail, mode %d", m.mode)
+	}
+	m.commitMark = ""
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := updatedModel.(Model).panels[CommitsPanel].selection(CommitsPanel); len(got) != 0 {
+		t.Errorf("esc should clear the selection, got %q", got)
+	}
+
+	// The current branch and the summary row of the Files panel can't be selected.
+	for _, tc := range []struct {
+		panel Panel
+		line  string
+		want  string
+	}{
+		{BranchesPanel, "2 days ago\t(*) → main", ""},
+		{BranchesPanel, "2 days ago\tfeature", "feature"},
+		{FilesPanel, "\t\t2 changed files\t.\t\t\t\t●", ""},
+		{FilesPanel, "  \tM \tmain.go\tsrc/main.go\t\t\t\t", "src/main.go"},
+		{StashPanel, "stash@{1}\tmain: WIP\tabc123", "abc123"},
+		{StashPanel, "No stashed changes.", ""},
+	} {
+		if got := selectionKey(tc.panel, tc.line); got != tc.want {
+			t.Errorf("selectionKey(%d, %q) = %q, want %q", tc.panel, tc.line, got, tc.want)
+		}
+	}
+}
+
+func TestModel_StashSelection(t *testing.T) {
+	m := initialModel()
+	m.focusedPanel = StashPanel
+	m.panels[StashPanel].lines = []string{"stash@{0}\tOn main: second\tbbb", "stash@{1}\tOn main: first\taaa"}
+	m.panels[StashPanel].cursor = 1
+	m.handleStashPanelKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	// Pushing a stash renumbers the others; the selection stays on the same stash.
+	content := "stash@{0}\tOn main: third\tccc\nstash@{1}\tOn main: second\tbbb\nstash@{2}\tOn main: first\taaa"
+	updatedModel, _ := m.Update(panelContentUpdatedMsg{panel: StashPanel, content: content})
+	m = updatedModel.(Model)
+	p := m.panels[StashPanel]
+	if got := p.selectedLines(StashPanel); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("expected the first stash to stay selected, got lines %v", got)
+	}
+
+	m.handleStashPanelKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if m.mode != modeConfirm || !strings.Contains(m.confirmMessage, "stash@{2}  On main: first") || strings.Contains(m.confirmMessage, "aaa") {
+		t.Errorf("unexpected drop confirmation %q", m.confirmMessage)
+	}
+}
+
+func TestModel_MoveCommit(t *testing.T) {
+	m := initialModel()
+	m.focusedPanel = CommitsPanel
+	content := "*\taaa\tAB\tThird\n|\\\n*\tbbb\tAB\tSecond\n*\tccc\tAB\tFirst"
+	m.panels[CommitsPanel].lines = strings.Split(content, "\n")
+	m.panels[CommitsPanel].cursor = 2
+
+	m.handleCommitsPanelKeys(tea.KeyMsg{Type: tea.KeyCtrlJ})
+	if m.mode != modeConfirm || !strings.Contains(m.confirmMessage, "Move bbb below its parent?") {
+		t.Errorf("moving a commit should ask for confirmation, got %q", m.confirmMessage)
+	}
+	m.mode = modeNormal
+
+	// After the move the cursor follows the commit, skipping graph lines.
+	updatedModel, _ := m.Update(panelContentUpdatedMsg{panel: CommitsPanel, content: content, selectCommit: "ccc0123456789"})
+	if cursor := updatedModel.(Model).panels[CommitsPanel].cursor; cursor != 3 {
+		t.Errorf("expected the cursor on the moved commit, got line %d", cursor)
+	}
+}
+
+func TestModel_Filter(t *testing.T) {
+	m := initialModel()
+	m.focusedPanel = BranchesPanel
+	all := []string{"1 day ago\t(*) → main", "2 days ago\tfeature/parser", "3 days ago\tfix-docs", "4 days ago\tfeature/panel"}
+	m.panels[BranchesPanel].lines = all
+	m.panels[BranchesPanel].cursor = 3
+	press := func(msg tea.KeyMsg) {
+		updatedModel, _ := m.Update(msg)
+		m = updatedModel.(Model)
+	}
+	typeText := func(text string) {
+		for _, r := range text {
+			press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+
+	typeText("/fp")
+	if m.mode != modeFilter {
+		t.Fatalf("/ should start typing a filter, got mode %d", m.mode)
+	}
+	want := []string{"2 days ago\tfeature/parser", "4 days ago\tfeature/panel"}
+	if p := m.panels[BranchesPanel]; !reflect.DeepEqual(p.lines, want) || p.cursor != 1 {
+		t.Fatalf("filtered to %q with the cursor on %d, want %q with the cursor kept on feature/panel", p.lines, p.cursor, want)
+	}
+	if got := m.panels[BranchesPanel].highlights[0]; !reflect.DeepEqual(got, [][]int{{0, 8}}) {
+		t.Errorf("unexpected highlights %v", got)
+	}
+
+	typeText("a")
+	press(tea.KeyMsg{Type: tea.KeyBackspace})
+	press(tea.KeyMsg{Type: tea.KeyUp})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != modeNormal || m.panels[BranchesPanel].filter != "fp" || m.panels[BranchesPanel].cursor != 0 {
+		t.Fatalf("enter should keep the filter %q with the cursor on 0, got %q on %d", "fp", m.panels[BranchesPanel].filter, m.panels[BranchesPanel].cursor)
+	}
+
+	// Actions work on the filtered lines.
+	typeText("t")
+	if got := m.panels[BranchesPanel].selection(BranchesPanel); !reflect.DeepEqual(got, []string{"feature/parser"}) {
+		t.Errorf("got selection %q in the filtered view", got)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if p := m.panels[BranchesPanel]; !reflect.DeepEqual(p.lines, all) || p.cursor != 1 || p.filter != "" {
+		t.Errorf("esc should restore every line with the cursor on feature/parser, got %q on %d", p.lines, p.cursor)
+	}
+}
+
 // newTestModel creates a new model with default dimensions and a calculated layout.
 func newTestModel() testModel {
 	m := initialModel()
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/viewport"
 )
@@ -32,6 +33,12 @@ type panel struct {
 	content  string
 	lines    []string
 	cursor   int
+	marked   map[string]bool // Selection keys of the lines toggled into the selection.
+	anchor   string          // Selection key of the line where a range selection started.
+
+	filter     string    // Fuzzy filter narrowing lines; "" shows every line.
+	allLines   []string  // Every line while a filter narrows lines.
+	highlights [][][]int // Matched rune positions in the columns of each of lines.
 }
 
 // nextPanel shifts focus to the next Panel.
@@ -49,3 +56,117 @@ func (m *Model) prevPanel() {
 	}
 	m.focusedPanel = m.focusedPanel - 1
 }
+
+// selectionKey identifies a line of a list panel across refreshes: the path
+// of a file or directory, the name of a branch, or the SHA of a commit or
+// stash. Lines that cannot be selected, such as the Files summary
+// row and the current branch, return "".
+func selectionKey(panel Panel, line string) string {
+	parts := strings.Split(line, "\t")
+	if len(parts) < 2 {
+		return ""
+	}
+	switch panel {
+	case FilesPanel:
+		if len(parts) < 4 || parts[3] == "." {
+			return ""
+		}
+		return parts[3]
+	case BranchesPanel:
+		if strings.HasPrefix(parts[1], "(*)") {
+			return ""
+		}
+		return strings.TrimSpace(parts[1])
+	case CommitsPanel:
+		return parts[1]
+	case StashPanel:
+		if len(parts) < 3 {
+			return ""
+		}
+		return parts[2]
+	}
+	return ""
+}
+
+// selectedLines returns the indices of the selected lines of a panel in
+// panel order: the toggled lines and, during a range selection, the lines
+// from the anchor to the cursor.
+func (p panel) selectedLines(id Panel) []int {
+	from, to := -1, -1
+	if p.anchor != "" && p.cursor < len(p.lines) {
+		from, to = p.cursor, p.cursor
+		for i, line := range p.lines {
+			if selectionKey(id, line) == p.anchor {
+				from, to = min(i, p.cursor), max(i, p.cursor)
+				break
+			}
+		}
+	}
+	var selected []int
+	for i, line := range p.lines {
+		key := selectionKey(id, line)
+		if key != "" && (p.marked[key] || (i >= from && i <= to)) {
+			selected = append(selected, i)
+		}
+	}
+	return selected
+}
+
+// selection returns the selection keys of the selected lines of a panel,
+// each once, in panel order.
+func (p panel) selection(id Panel) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, i := range p.selectedLines(id) {
+		key := selectionKey(id, p.lines[i])
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// toggleMark adds the line under the cursor to the selection, or removes it.
+func (p *panel) toggleMark(id Panel) {
+	if p.cursor >= len(p.lines) {
+		return
+	}
+	key := selectionKey(id, p.lines[p.cursor])
+	if key == "" {
+		return
+	}
+	if p.marked == nil {
+		p.marked = make(map[string]bool)
+	}
+	if p.marked[key] {
+		delete(p.marked, key)
+	} else {
+		p.marked[key] = true
+	}
+}
+
+// toggleRange starts a range selection at the cursor. When one is already
+// running, its lines are kept in the selection so that another range can be
+// started.
+func (p *panel) toggleRange(id Panel) {
+	if p.anchor != "" {
+		for _, key := range p.selection(id) {
+			if p.marked == nil {
+				p.marked = make(map[string]bool)
+			}
+			p.marked[key] = true
+		}
+		p.anchor = ""
+		return
+	}
+	if p.cursor < len(p.lines) {
+		p.anchor = selectionKey(id, p.lines[p.cursor])
+	}
+}
+
+// clearSelection drops the toggled lines and ends a range selection.
+func (p *panel) clearSelection() {
+	p.marked = nil
+	p.anchor = ""
+}
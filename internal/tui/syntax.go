@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// detectLexer returns the lexer for a file, detected from its name and, for
+// scripts without an extension, from the shebang on its first line. It
+// returns nil when the language is unknown.
+func detectLexer(path, firstLine string) chroma.Lexer {
+	lexer := lexers.Match(filepath.Base(path))
+	if lexer == nil {
+		if interpreter := shebangInterpreter(firstLine); interpreter != "" {
+			lexer = lexers.Get(interpreter)
+			if lexer == nil {
+				// "python3.12" is not an alias, "python" is.
+				lexer = lexers.Get(strings.TrimRight(interpreter, "0123456789."))
+			}
+		}
+	}
+	if lexer == nil || lexer.Config().Name == "plaintext" {
+		return nil
+	}
+	return chroma.Coalesce(lexer)
+}
+
+// shebangInterpreter returns the interpreter named by a "#!" line, e.g.
+// "python3" for "#!/usr/bin/env python3".
+func shebangInterpreter(line string) string {
+	command, ok := strings.CutPrefix(line, "#!")
+	if !ok {
+		return ""
+	}
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		// Skip options such as `env -S`.
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = filepath.Base(field)
+				break
+			}
+		}
+	}
+	return interpreter
+}
+
+// tokenizeLine splits a single line into syntax tokens. Lines are tokenized
+// on their own so that any part of a file or diff can be highlighted without
+// the lines before it; constructs spanning lines, such as block comments,
+// are only recognized on their first line.
+func tokenizeLine(lexer chroma.Lexer, line string) []chroma.Token {
+	iterator, err := lexer.Tokenise(nil, line+"\n")
+	if err != nil {
+		return []chroma.Token{{Type: chroma.Text, Value: line}}
+	}
+	tokens := iterator.Tokens()
+	// Drop the newline added above so that tokens cover exactly line.
+	if n := len(tokens); n > 0 {
+		tokens[n-1].Value = strings.TrimSuffix(tokens[n-1].Value, "\n")
+	}
+	return tokens
+}
+
+// syntaxStyle returns the theme style of a token type.
+func (m Model) syntaxStyle(tokenType chroma.TokenType) lipgloss.Style {
+	s := m.theme.Syntax
+	switch {
+	case tokenType.InCategory(chroma.Comment):
+		return s.Comment
+	case tokenType == chroma.KeywordType || tokenType == chroma.NameClass || tokenType == chroma.NameBuiltin:
+		return s.Type
+	case tokenType.InCategory(chroma.Keyword):
+		return s.Keyword
+	case tokenType == chroma.NameFunction || tokenType == chroma.NameFunctionMagic || tokenType == chroma.NameDecorator:
+		return s.Function
+	case tokenType == chroma.NameTag || tokenType == chroma.NameAttribute:
+		return s.Keyword
+	case tokenType.InSubCategory(chroma.LiteralString):
+		return s.String
+	case tokenType.InSubCategory(chroma.LiteralNumber), tokenType == chroma.NameConstant:
+		return s.Number
+	case tokenType.InCategory(chroma.Operator):
+		return s.Operator
+	case tokenType.InCategory(chroma.Punctuation):
+		return s.Punctuation
+	}
+	return m.theme.NormalText
+}
+
+// renderSyntax renders line with syntax colors. When background is set it is
+// layered under the whole line, and spans are emphasized with wordBackground.
+func (m Model) renderSyntax(lexer chroma.Lexer, line string, spans []diffSpan, background, wordBackground lipgloss.TerminalColor) string {
+	var b strings.Builder
+	offset := 0 // Rune offset of the current token.
+	spanIndex := 0
+	for _, token := range tokenizeLine(lexer, line) {
+		style := m.syntaxStyle(token.Type)
+		runes := []rune(token.Value)
+		for len(runes) > 0 {
+			// Split the token where a changed span starts or ends.
+			for spanIndex < len(spans) && spans[spanIndex].end <= offset {
+				spanIndex++
+			}
+			n, inSpan := len(runes), false
+			if spanIndex < len(spans) {
+				span := spans[spanIndex]
+				if offset >= span.start {
+					n, inSpan = min(n, span.end-offset), true
+				} else {
+					n = min(n, span.start-offset)
+				}
+			}
+
+			pieceStyle := style
+			switch {
+			case inSpan:
+				pieceStyle = style.Background(wordBackground)
+			case background != nil:
+				pieceStyle = style.Background(background)
+			}
+			b.WriteString(pieceStyle.Render(string(runes[:n])))
+			runes = runes[n:]
+			offset += n
+		}
+	}
+	return b.String()
+}
+
+// renderedRows is content for the Main panel whose rows can be syntax
+// highlighted on demand, so that only the visible part of a large file or
+// diff is highlighted.
+type renderedRows struct {
+	rows []string
+	// highlight renders a row with syntax colors. It is nil for rows without
+	// syntax and for rows that have already been highlighted.
+	highlight []func() string
+}
+
+// add appends a row and the function rendering it with syntax colors, which
+// may be nil.
+func (r *renderedRows) add(row string, highlight func() string) {
+	r.rows = append(r.rows, row)
+	r.highlight = append(r.highlight, highlight)
+}
+
+// highlightRange highlights the rows [from, to) and reports whether any
+// row changed.
+func (r *renderedRows) highlightRange(from, to int) bool {
+	changed := false
+	for i := max(from, 0); i < min(to, len(r.rows)); i++ {
+		if r.highlight[i] != nil {
+			r.rows[i] = r.highlight[i]()
+			r.highlight[i] = nil
+			changed = true
+		}
+	}
+	return changed
+}
+
+// highlightSmall highlights all rows when there are few enough of them to
+// do it up front.
+func (r *renderedRows) highlightSmall() {
+	if len(r.rows) <= syntaxEagerMaxRows {
+		r.highlightRange(0, len(r.rows))
+	}
+}
+
+// truncate keeps the first limit rows and returns the number of rows cut off.
+func (r *renderedRows) truncate(limit int) int {
+	hidden := len(r.rows) - limit
+	if hidden <= 0 {
+		return 0
+	}
+	r.rows, r.highlight = r.rows[:limit], r.highlight[:limit]
+	return hidden
+}
+
+// String joins the rows as they are currently rendered.
+func (r *renderedRows) String() string {
+	return strings.Join(r.rows, "\n")
+}
+
+// highlightMainViewport highlights the rows of the Main panel that are
+// scrolled into view, plus a screen's worth of rows around them.
+func (m *Model) highlightMainViewport() {
+	if m.mainRows == nil {
+		return
+	}
+	vp := &m.panels[MainPanel].viewport
+	if m.mainRows.highlightRange(vp.YOffset-vp.Height, vp.YOffset+2*vp.Height) {
+		content := m.mainRows.String()
+		m.panels[MainPanel].content = content
+		vp.SetContent(content)
+	}
+}
@@ -12,6 +12,9 @@ type Palette struct {
 	BrightBlack, BrightRed, BrightGreen, BrightYellow, BrightBlue, BrightMagenta, BrightCyan, BrightWhite,
 	DarkBlack, DarkRed, DarkGreen, DarkYellow, DarkBlue, DarkMagenta, DarkCyan, DarkWhite,
 	Bg, Fg string
+	// AddedBg and RemovedBg are subtle tints behind syntax highlighted diff
+	// lines.
+	AddedBg, RemovedBg string
 }
 
 // Palettes holds all the available color palettes.
@@ -48,8 +51,10 @@ var Palettes = map[string]Palette{
 		DarkWhite:   "#8b949e",
 
 		// Special
-		Bg: "#0d1117",
-		Fg: "#c9d1d9",
+		Bg:        "#0d1117",
+		Fg:        "#c9d1d9",
+		AddedBg:   "#12261e",
+		RemovedBg: "#25171c",
 	},
 	"Gruvbox": {
 		// Normal
@@ -83,42 +88,60 @@ var Palettes = map[string]Palette{
 		DarkWhite:   "#928374",
 
 		// Special
-		Bg: "#282828",
-		Fg: "#ebdbb2",
+		Bg:        "#282828",
+		Fg:        "#ebdbb2",
+		AddedBg:   "#32361a",
+		RemovedBg: "#3c1f1e",
 	},
 }
 
 // Theme represents the styles for different components of the UI.
 type Theme struct {
-	ActiveTitle    lipgloss.Style
-	InactiveTitle  lipgloss.Style
-	NormalText     lipgloss.Style
-	HelpTitle      lipgloss.Style
-	HelpKey        lipgloss.Style
-	HelpButton     lipgloss.Style
-	ScrollbarThumb lipgloss.Style
-	SelectedLine   lipgloss.Style
-	Hyperlink      lipgloss.Style
-	WelcomeHeading lipgloss.Style
-	WelcomeMsg     lipgloss.Style
-	UserName       lipgloss.Style
-	GitStaged      lipgloss.Style
-	GitUnstaged    lipgloss.Style
-	GitUntracked   lipgloss.Style
-	GitConflicted  lipgloss.Style
-	BranchCurrent  lipgloss.Style
-	BranchDate     lipgloss.Style
-	CommitSHA      lipgloss.Style
-	CommitAuthor   lipgloss.Style
-	CommitMerge    lipgloss.Style
-	GraphEdge      lipgloss.Style
-	GraphNode      lipgloss.Style
-	GraphColors    []lipgloss.Style
-	StashName      lipgloss.Style
-	StashMessage   lipgloss.Style
-	ActiveBorder   BorderStyle
-	InactiveBorder BorderStyle
-	Tree           TreeStyle
+	ActiveTitle     lipgloss.Style
+	InactiveTitle   lipgloss.Style
+	NormalText      lipgloss.Style
+	HelpTitle       lipgloss.Style
+	HelpKey         lipgloss.Style
+	HelpButton      lipgloss.Style
+	ScrollbarThumb  lipgloss.Style
+	SelectedLine    lipgloss.Style
+	Hyperlink       lipgloss.Style
+	WelcomeHeading  lipgloss.Style
+	WelcomeMsg      lipgloss.Style
+	UserName        lipgloss.Style
+	GitStaged       lipgloss.Style
+	GitUnstaged     lipgloss.Style
+	GitUntracked    lipgloss.Style
+	GitConflicted   lipgloss.Style
+	FileSection     lipgloss.Style
+	BranchCurrent   lipgloss.Style
+	BranchDate      lipgloss.Style
+	CommitSHA       lipgloss.Style
+	CommitAuthor    lipgloss.Style
+	CommitMerge     lipgloss.Style
+	GraphEdge       lipgloss.Style
+	GraphNode       lipgloss.Style
+	GraphColors     []lipgloss.Style
+	StashName       lipgloss.Style
+	StashMessage    lipgloss.Style
+	LintOK          lipgloss.Style
+	LintViolation   lipgloss.Style
+	FuzzyMatch      lipgloss.Style
+	DiffAdded       lipgloss.Style
+	DiffRemoved     lipgloss.Style
+	DiffAddedWord   lipgloss.Style
+	DiffRemovedWord lipgloss.Style
+	DiffAddedLine   lipgloss.Style
+	DiffRemovedLine lipgloss.Style
+	DiffHeader      lipgloss.Style
+	DiffHunk        lipgloss.Style
+	DiffLineNumber  lipgloss.Style
+	MarkedLine      lipgloss.Style
+	StateBanner     lipgloss.Style
+	ActiveBorder    BorderStyle
+	InactiveBorder  BorderStyle
+	Tree            TreeStyle
+	Syntax          SyntaxStyle
 }
 
 // BorderStyle defines the characters and styles for a panel's border.
@@ -134,6 +157,11 @@ type BorderStyle struct {
 	Style       lipgloss.Style
 }
 
+// SyntaxStyle defines the styles of the token types in highlighted code.
+type SyntaxStyle struct {
+	Keyword, Type, Function, String, Number, Comment, Operator, Punctuation lipgloss.Style
+}
+
 // TreeStyle defines the characters used to render the file tree.
 type TreeStyle struct {
 	Connector, ConnectorLast, Prefix, PrefixLast string
@@ -167,6 +195,7 @@ func NewThemeFromPalette(p Palette) Theme {
 		GitUnstaged:    lipgloss.NewStyle().Foreground(lipgloss.Color(p.Red)),
 		GitUntracked:   lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightBlack)),
 		GitConflicted:  lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightRed)).Bold(true),
+		FileSection:    lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightWhite)).Bold(true),
 		BranchCurrent:  lipgloss.NewStyle().Foreground(lipgloss.Color(p.Green)).Bold(true),
 		BranchDate:     lipgloss.NewStyle().Foreground(lipgloss.Color(p.Yellow)),
 		CommitSHA:      lipgloss.NewStyle().Foreground(lipgloss.Color(p.Yellow)),
@@ -186,8 +215,22 @@ func NewThemeFromPalette(p Palette) Theme {
 			lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightMagenta)),
 			lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightCyan)),
 		},
-		StashName:    lipgloss.NewStyle().Foreground(lipgloss.Color(p.Yellow)),
-		StashMessage: lipgloss.NewStyle().Foreground(lipgloss.Color(p.Fg)),
+		StashName:       lipgloss.NewStyle().Foreground(lipgloss.Color(p.Yellow)),
+		StashMessage:    lipgloss.NewStyle().Foreground(lipgloss.Color(p.Fg)),
+		LintOK:          lipgloss.NewStyle().Foreground(lipgloss.Color(p.Green)),
+		LintViolation:   lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightRed)),
+		FuzzyMatch:      lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightYellow)).Bold(true),
+		DiffAdded:       lipgloss.NewStyle().Foreground(lipgloss.Color(p.Green)),
+		DiffRemoved:     lipgloss.NewStyle().Foreground(lipgloss.Color(p.Red)),
+		DiffAddedWord:   lipgloss.NewStyle().Background(lipgloss.Color(p.DarkGreen)).Foreground(lipgloss.Color(p.BrightWhite)),
+		DiffAddedLine:   lipgloss.NewStyle().Background(lipgloss.Color(p.AddedBg)),
+		DiffRemovedLine: lipgloss.NewStyle().Background(lipgloss.Color(p.RemovedBg)),
+		DiffRemovedWord: lipgloss.NewStyle().Background(lipgloss.Color(p.DarkRed)).Foreground(lipgloss.Color(p.BrightWhite)),
+		DiffHeader:      lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightWhite)).Bold(true),
+		DiffHunk:        lipgloss.NewStyle().Foreground(lipgloss.Color(p.Cyan)),
+		DiffLineNumber:  lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightBlack)),
+		MarkedLine:      lipgloss.NewStyle().Background(lipgloss.Color(p.DarkMagenta)).Foreground(lipgloss.Color(p.BrightWhite)),
+		StateBanner:     lipgloss.NewStyle().Background(lipgloss.Color(p.Yellow)).Foreground(lipgloss.Color(p.Bg)).Bold(true),
 		ActiveBorder: BorderStyle{
 			Top: borderTop, Bottom: borderBottom, Left: borderLeft, Right: borderRight,
 			TopLeft: borderTopLeft, TopRight: borderTopRight, BottomLeft: borderBottomLeft, BottomRight: borderBottomRight,
@@ -204,6 +247,16 @@ func NewThemeFromPalette(p Palette) Theme {
 			Prefix:        treePrefix,
 			PrefixLast:    treePrefixLast,
 		},
+		Syntax: SyntaxStyle{
+			Keyword:     lipgloss.NewStyle().Foreground(lipgloss.Color(p.Red)),
+			Type:        lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightCyan)),
+			Function:    lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightMagenta)),
+			String:      lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightBlue)),
+			Number:      lipgloss.NewStyle().Foreground(lipgloss.Color(p.Blue)),
+			Comment:     lipgloss.NewStyle().Foreground(lipgloss.Color(p.BrightBlack)).Italic(true),
+			Operator:    lipgloss.NewStyle().Foreground(lipgloss.Color(p.Yellow)),
+			Punctuation: lipgloss.NewStyle().Foreground(lipgloss.Color(p.Fg)),
+		},
 	}
 }
 
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/gitxtui/gitx/internal/git"
+)
+
+// trailerKind describes a commit trailer token offered by the trailer picker.
+type trailerKind struct {
+	token    string
+	isPerson bool // Person trailers pick from the repository's authors.
+}
+
+// defaultTrailerKinds are the trailers offered when no extra ones are configured.
+var defaultTrailerKinds = []trailerKind{
+	{token: "Co-authored-by", isPerson: true},
+	{token: "Reviewed-by", isPerson: true},
+	{token: "Signed-off-by", isPerson: true},
+	{token: "Helped-by", isPerson: true},
+	{token: "Fixes"},
+	{token: "Refs"},
+}
+
+// trailerPicker holds the state of the trailer picker opened from the commit pop-up.
+type trailerPicker struct {
+	kinds     []trailerKind
+	kindIndex int
+	input     textinput.Model
+	authors   []string
+	matches   []fuzzyMatch
+	cursor    int
+}
+
+// newTrailerPicker creates a picker with the repository's authors and the
+// trailer tokens configured in `gitx.trailers.extra` (e.g. "Jira,Ticket").
+func newTrailerPicker(gc *git.GitCommands) trailerPicker {
+	kinds := append([]trailerKind{}, defaultTrailerKinds...)
+	if extra, err := gc.GetConfigValue("gitx.trailers.extra"); err == nil {
+		for _, token := range splitConfigList(extra) {
+			kinds = append(kinds, trailerKind{token: token})
+		}
+	}
+	authors, _ := gc.GetAuthors()
+
+	input := textinput.New()
+	input.CharLimit = 256
+	input.Width = 60
+	input.Focus()
+
+	p := trailerPicker{kinds: kinds, authors: authors, input: input}
+	p.refilter()
+	return p
+}
+
+// kind returns the currently selected trailer kind.
+func (p *trailerPicker) kind() trailerKind {
+	return p.kinds[p.kindIndex]
+}
+
+// cycleKind selects the next (delta 1) or previous (delta -1) trailer kind.
+func (p *trailerPicker) cycleKind(delta int) {
+	p.kindIndex = (p.kindIndex + delta + len(p.kinds)) % len(p.kinds)
+	p.refilter()
+}
+
+// refilter updates the candidate list from the current input.
+func (p *trailerPicker) refilter() {
+	p.matches = nil
+	p.cursor = 0
+	if p.kind().isPerson {
+		p.matches = fuzzyFilter(p.input.Value(), p.authors)
+		p.input.Placeholder = "Search authors"
+	} else {
+		p.input.Placeholder = "Value, e.g. #123 or PROJ-42"
+	}
+}
+
+// moveCursor moves the candidate selection by delta, clamped to the list.
+func (p *trailerPicker) moveCursor(delta int) {
+	p.cursor += delta
+	if p.cursor >= len(p.matches) {
+		p.cursor = len(p.matches) - 1
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+}
+
+// trailer returns the trailer to insert, e.g. "Fixes: #123", or "" when
+// there is nothing to insert.
+func (p *trailerPicker) trailer() string {
+	value := strings.TrimSpace(p.input.Value())
+	if p.kind().isPerson && p.cursor < len(p.matches) {
+		value = p.authors[p.matches[p.cursor].index]
+	}
+	if value == "" {
+		return ""
+	}
+	return p.kind().token + ": " + value
+}
+
+// splitCommitMessage splits a full commit message into the title and
+// description shown in the commit pop-up.
+func splitCommitMessage(message string) (title, description string) {
+	message = strings.TrimRight(message, "\n")
+	title, description, _ = strings.Cut(message, "\n")
+	return title, strings.TrimLeft(description, "\n")
+}
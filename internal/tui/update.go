@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -15,13 +16,21 @@ var keys = DefaultKeyMap()
 
 // panelContentUpdatedMsg is sent when new content for a panel has been fetched.
 type panelContentUpdatedMsg struct {
-	panel   Panel
-	content string
+	panel     Panel
+	content   string
+	repoState git.RepoState // Only set for the StatusPanel.
+	// The line counts of the staged and unstaged changes, only set for the
+	// FilesPanel.
+	stagedStats, unstagedStats []git.FileStat
+	// The full SHA of a commit to put the cursor on, only set for the
+	// CommitsPanel.
+	selectCommit string
 }
 
 // mainContentUpdatedMsg is sent when the content for the main panel has been fetched.
 type mainContentUpdatedMsg struct {
 	content string
+	rows    *renderedRows // Set when the content can be syntax highlighted.
 }
 
 // lineClickedMsg is sent when a user clicks on a line in a selectable panel.
@@ -30,6 +39,10 @@ type lineClickedMsg struct {
 	lineIndex int
 }
 
+// selectionDoneMsg is sent when a bulk action on the selection of a panel
+// was confirmed, to clear the selection.
+type selectionDoneMsg struct{ panel Panel }
+
 // fileWatcherMsg is sent by the file watcher when the repository state changes.
 type fileWatcherMsg struct{}
 
@@ -48,6 +61,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateConfirm(msg)
 	case modeCommit:
 		return m.updateCommit(msg)
+	case modeTrailer:
+		return m.updateTrailer(msg)
+	case modeMenu:
+		return m.updateMenu(msg)
+	case modeAbsorb:
+		return m.updateAbsorb(msg)
+	case modeFilter:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.updateFilter(msg)
+		}
 	}
 
 	var cmd tea.Cmd
@@ -63,42 +86,45 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case mainContentUpdatedMsg:
 		m.panels[MainPanel].content = msg.content
 		m.panels[MainPanel].viewport.SetContent(msg.content)
+		m.mainRows = msg.rows
+		m.highlightMainViewport()
 		return m, nil
 
+	case comparisonLoadedMsg:
+		c := m.comparison
+		if c == nil || c.from != msg.from || c.to != msg.to || c.threeDot != msg.threeDot {
+			return m, nil // The comparison changed while loading.
+		}
+		c.files = msg.files
+		c.diffRows = msg.diffRows
+		c.cursor = min(c.cursor, max(len(c.files)-1, 0))
+		return m, m.showComparisonList()
+
 	case panelContentUpdatedMsg:
-		var selectedPath string
+		if msg.panel == StatusPanel && msg.repoState != m.repoState {
+			// The banner changes the height of the Status panel.
+			m.repoState = msg.repoState
+			m = m.recalculateLayout()
+		}
+		var selectedPath, selectedStatus string
 		// If the FilesPanel is being updated, try to find the path of the
 		// currently selected item to preserve the cursor position after the refresh.
 		if msg.panel == FilesPanel && m.panels[FilesPanel].cursor < len(m.panels[FilesPanel].lines) {
 			line := m.panels[FilesPanel].lines[m.panels[FilesPanel].cursor]
 			parts := strings.Split(line, "\t")
-			if len(parts) == 4 {
-				selectedPath = parts[3]
+			if len(parts) >= 4 {
+				selectedPath, selectedStatus = parts[3], parts[1]
 			}
 		}
 
 		oldCursor := m.panels[msg.panel].cursor
 
 		if msg.panel == FilesPanel {
-			root := BuildTree(msg.content)
-			renderedTree := root.Render(m.theme)
-			m.panels[FilesPanel].lines = renderedTree
-			m.panels[FilesPanel].viewport.SetContent(strings.Join(renderedTree, "\n"))
-
-			// Restore the cursor to the previously selected file path.
-			newCursorPos := 0 // Default to top.
-			if selectedPath != "" {
-				for i, line := range renderedTree {
-					parts := strings.Split(line, "\t")
-					if len(parts) == 4 && parts[3] == selectedPath {
-						newCursorPos = i
-						break
-					}
-				}
-			}
-			m.panels[FilesPanel].cursor = newCursorPos
+			m.fileTree = BuildTree(msg.content)
+			m.fileTree.SetLineCounts(msg.stagedStats, msg.unstagedStats)
+			m.renderFileTree(selectedPath, selectedStatus)
 		} else {
-			lines := strings.Split(msg.content, "\n")
+			lines := m.panels[msg.panel].narrow(msg.panel, strings.Split(msg.content, "\n"))
 			m.panels[msg.panel].lines = lines
 			m.panels[msg.panel].viewport.SetContent(msg.content)
 			m.panels[msg.panel].content = msg.content
@@ -112,8 +138,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.panels[msg.panel].cursor = 0
 			}
 		}
+		if msg.selectCommit != "" {
+			p := &m.panels[CommitsPanel]
+			for i, line := range p.lines {
+				parts := strings.Split(line, "\t")
+				if len(parts) >= 2 && parts[1] != "" && strings.HasPrefix(msg.selectCommit, parts[1]) {
+					p.cursor = i
+					break
+				}
+			}
+			if p.cursor < p.viewport.YOffset {
+				p.viewport.SetYOffset(p.cursor)
+			}
+			if p.cursor >= p.viewport.YOffset+p.viewport.Height {
+				p.viewport.SetYOffset(p.cursor - p.viewport.Height + 1)
+			}
+		}
 		return m, m.updateMainPanel()
 
+	case selectionDoneMsg:
+		m.panels[msg.panel].clearSelection()
+		return m, nil
+
 	case fileWatcherMsg:
 		// When the repository changes, trigger a content refresh for all panels.
 		return m, tea.Batch(
@@ -138,6 +184,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		m.activeSourcePanel = msg.panel
+		m.comparison = nil
+		m.mainRowLimit = 0
 		m.panels[MainPanel].viewport.GotoTop()
 		return m, m.updateMainPanel()
 
@@ -170,6 +218,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 
 		case key.Matches(msg, keys.Escape):
+			if m.focusedPanel == MainPanel && m.comparison != nil {
+				return m, m.closeComparison()
+			}
+			if p := &m.panels[m.focusedPanel]; p.filter != "" {
+				p.setFilter(m.focusedPanel, "")
+				return m, m.updateMainPanel()
+			}
+			m.commitMark = ""
+			m.panels[m.focusedPanel].clearSelection()
 			return m, nil
 
 		case key.Matches(msg, keys.ToggleHelp):
@@ -202,6 +259,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update the active source panel and main panel content if the new focus is a source panel
 		if m.focusedPanel != MainPanel && m.focusedPanel != SecondaryPanel {
 			m.activeSourcePanel = m.focusedPanel
+			m.comparison = nil
+			m.mainRowLimit = 0
 			m.panels[MainPanel].viewport.GotoTop() // Reset main panel scroll on source change
 			cmd = m.updateMainPanel()
 			cmds = append(cmds, cmd)
@@ -213,6 +272,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// The original viewport update logic for scrolling
 	m.panels[m.focusedPanel].viewport, cmd = m.panels[m.focusedPanel].viewport.Update(msg)
 	cmds = append(cmds, cmd)
+	m.highlightMainViewport()
 
 	return m, tea.Batch(cmds...)
 }
@@ -244,12 +304,21 @@ func (m Model) updateCommit(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if key.Matches(msg, keys.AddTrailer) {
+			m.mode = modeTrailer
+			m.trailerPicker = newTrailerPicker(m.git)
+			return m, nil
+		}
 		switch msg.Type {
 		case tea.KeyEnter:
 			// Only submit if focused on title input
 			if m.textInput.Focused() {
 				title := m.textInput.Value()
 				description := m.descriptionInput.Value()
+				if m.commitLint.blocksSubmit() && len(m.commitLint.lint(buildCommitMessage(title, description))) > 0 {
+					// Violations are already listed in the pop-up; keep it open.
+					return m, nil
+				}
 				cmd = m.commitCallback(title, description)
 				m.mode = modeNormal
 				m.textInput.Reset()
@@ -284,6 +353,169 @@ func (m Model) updateCommit(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// updateTrailer handles updates when the trailer picker is open on top of
+// the commit pop-up.
+func (m Model) updateTrailer(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	p := &m.trailerPicker
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.mode = modeCommit
+			return m, nil
+		case tea.KeyTab:
+			p.cycleKind(1)
+			return m, nil
+		case tea.KeyShiftTab:
+			p.cycleKind(-1)
+			return m, nil
+		case tea.KeyUp, tea.KeyCtrlP:
+			p.moveCursor(-1)
+			return m, nil
+		case tea.KeyDown, tea.KeyCtrlN:
+			p.moveCursor(1)
+			return m, nil
+		case tea.KeyEnter:
+			m.mode = modeCommit
+			trailer := p.trailer()
+			if trailer == "" {
+				return m, nil
+			}
+			message := buildCommitMessage(m.textInput.Value(), m.descriptionInput.Value())
+			updated, err := m.git.InterpretTrailers(message, []string{trailer})
+			if err != nil {
+				return m, func() tea.Msg { return errMsg{err} }
+			}
+			title, description := splitCommitMessage(updated)
+			m.textInput.SetValue(title)
+			m.descriptionInput.SetValue(description)
+			return m, nil
+		}
+	}
+
+	oldValue := p.input.Value()
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != oldValue {
+		p.refilter()
+	}
+	return m, cmd
+}
+
+// openMenu shows the menu pop-up with the given title and items.
+func (m *Model) openMenu(title string, items []menuItem) {
+	m.mode = modeMenu
+	m.menuTitle = title
+	m.menuItems = items
+	m.menuCursor = 0
+}
+
+// updateMenu handles updates when the menu pop-up is open.
+func (m Model) updateMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Up):
+			if m.menuCursor > 0 {
+				m.menuCursor--
+			}
+		case key.Matches(msg, keys.Down):
+			if m.menuCursor < len(m.menuItems)-1 {
+				m.menuCursor++
+			}
+		case msg.Type == tea.KeyEnter:
+			m.mode = modeNormal
+			if m.menuCursor < len(m.menuItems) {
+				cmd := m.menuItems[m.menuCursor].action(&m)
+				return m, cmd
+			}
+		case msg.Type == tea.KeyEsc, key.Matches(msg, keys.Quit):
+			m.mode = modeNormal
+		}
+	}
+	return m, nil
+}
+
+// updateAbsorb handles updates when the absorb review list is open.
+func (m Model) updateAbsorb(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		r := &m.absorbReview
+		switch {
+		case key.Matches(msg, keys.Up):
+			r.moveCursor(-1)
+		case key.Matches(msg, keys.Down):
+			r.moveCursor(1)
+		case msg.Type == tea.KeyTab, msg.Type == tea.KeyRight, msg.String() == "l":
+			r.cycleTarget(1)
+		case msg.Type == tea.KeyShiftTab, msg.Type == tea.KeyLeft, msg.String() == "h":
+			r.cycleTarget(-1)
+		case msg.Type == tea.KeyEnter:
+			if r.absorbedCount() == 0 {
+				return m, nil
+			}
+			m.mode = modeNormal
+			plan := r.plan
+			return m, func() tea.Msg {
+				_, err := m.git.ApplyAbsorb(plan)
+				if err != nil {
+					return errMsg{err}
+				}
+				return tea.Batch(
+					m.fetchPanelContent(FilesPanel),
+					m.fetchPanelContent(CommitsPanel),
+				)
+			}
+		case msg.Type == tea.KeyEsc, key.Matches(msg, keys.Quit):
+			m.mode = modeNormal
+		}
+	}
+	return m, nil
+}
+
+// openCommitPopup switches to commit mode with the inputs prefilled from
+// message and fresh lint rules. The callback receives the title and
+// description on submit.
+func (m *Model) openCommitPopup(message string, callback func(title, description string) tea.Cmd) {
+	title, description := splitCommitMessage(message)
+	m.mode = modeCommit
+	m.textInput.SetValue(title)
+	m.descriptionInput.SetValue(description)
+	m.descriptionInput.Blur()
+	m.textInput.Focus()
+	m.commitLint = loadCommitLintRules(m.git)
+	m.commitCallback = callback
+}
+
+// updateFilter edits the filter of the focused list panel while it is typed,
+// narrowing the panel with every key. Enter keeps the filter, Esc drops it.
+func (m Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := &m.panels[m.focusedPanel]
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.mode = modeNormal
+		return m, nil
+	case tea.KeyEsc:
+		m.mode = modeNormal
+		p.setFilter(m.focusedPanel, "")
+	case tea.KeyUp, tea.KeyDown:
+		_, cmd := m.handleCursorMovement(msg)
+		return m, cmd
+	case tea.KeyBackspace:
+		runes := []rune(p.filter)
+		if len(runes) == 0 {
+			return m, nil
+		}
+		p.setFilter(m.focusedPanel, string(runes[:len(runes)-1]))
+	case tea.KeySpace:
+		p.setFilter(m.focusedPanel, p.filter+" ")
+	case tea.KeyRunes:
+		p.setFilter(m.focusedPanel, p.filter+string(msg.Runes))
+	default:
+		return m, nil
+	}
+	m.mainRowLimit = 0
+	m.panels[MainPanel].viewport.GotoTop()
+	return m, m.updateMainPanel()
+}
+
 // updateConfirm handles updates when in confirmation mode.
 func (m Model) updateConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
@@ -307,6 +539,8 @@ func (m Model) updateConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) fetchPanelContent(panel Panel) tea.Cmd {
 	return func() tea.Msg {
 		var content, repoName, branchName string
+		var repoState git.RepoState
+		var stagedStats, unstagedStats []git.FileStat
 		var err error
 		switch panel {
 		case StatusPanel:
@@ -315,9 +549,18 @@ func (m Model) fetchPanelContent(panel Panel) tea.Cmd {
 				repo := m.theme.BranchCurrent.Render(repoName)
 				branch := m.theme.BranchCurrent.Render(branchName)
 				content = fmt.Sprintf("%s → %s", repo, branch)
+				repoState, err = m.git.GetRepoState()
+				if err == nil && repoState.InProgress() {
+					content += "\n" + m.renderRepoStateBanner(repoState)
+				}
 			}
 		case FilesPanel:
 			content, err = m.git.GetStatus(git.StatusOptions{Porcelain: true})
+			if err == nil {
+				// The line counts are optional: a failure leaves them out.
+				stagedStats, _ = m.git.DiffNumstat(git.DiffOptions{Cached: true})
+				unstagedStats, _ = m.git.DiffNumstat(git.DiffOptions{})
+			}
 		case BranchesPanel:
 			var branchList []*git.Branch
 			branchList, err = m.git.GetBranches()
@@ -358,7 +601,8 @@ func (m Model) fetchPanelContent(panel Panel) tea.Cmd {
 				} else {
 					var builder strings.Builder
 					for _, s := range stashList {
-						line := fmt.Sprintf("%s\t%s: %s", s.Name, s.Branch, s.Message)
+						// The SHA is hidden; it identifies the stash across refreshes.
+						line := fmt.Sprintf("%s\t%s: %s\t%s", s.Name, s.Branch, s.Message, s.SHA)
 						builder.WriteString(line + "\n")
 					}
 					content = strings.TrimSpace(builder.String())
@@ -376,15 +620,36 @@ func (m Model) fetchPanelContent(panel Panel) tea.Cmd {
 		if err != nil {
 			content = "Error: " + err.Error()
 		}
-		return panelContentUpdatedMsg{panel: panel, content: content}
+		return panelContentUpdatedMsg{panel: panel, content: content, repoState: repoState, stagedStats: stagedStats, unstagedStats: unstagedStats}
+	}
+}
+
+// fetchCommitsSelecting refreshes the Commits panel and puts the cursor on
+// the given commit.
+func (m Model) fetchCommitsSelecting(sha string) tea.Cmd {
+	fetch := m.fetchPanelContent(CommitsPanel)
+	return func() tea.Msg {
+		msg := fetch()
+		if updated, ok := msg.(panelContentUpdatedMsg); ok {
+			updated.selectCommit = sha
+			return updated
+		}
+		return msg
 	}
 }
 
 // updateMainPanel returns a command that fetches the content for the main panel
 // based on the currently active source panel.
 func (m *Model) updateMainPanel() tea.Cmd {
+	if c := m.comparison; c != nil {
+		if c.file != "" {
+			return m.loadComparisonFile(*c)
+		}
+		return m.loadComparison(*c)
+	}
 	return func() tea.Msg {
 		var content string
+		var rows *renderedRows
 		var err error
 		switch m.activeSourcePanel {
 		case StatusPanel:
@@ -398,35 +663,49 @@ func (m *Model) updateMainPanel() tea.Cmd {
 				line := m.panels[FilesPanel].lines[m.panels[FilesPanel].cursor]
 				parts := strings.Split(line, "\t")
 
-				if len(parts) == 4 {
+				if len(parts) >= 4 {
 					status := parts[1]
 					path := parts[3] // Always use the full path from the 4th column
 
 					if path != "" {
 						if status == "" { // It's a directory
-							content, err = m.git.ShowDiff(git.DiffOptions{Color: true, Commit1: "HEAD", Commit2: path})
+							content, err = m.git.ShowDiff(m.diffDisplayOptions(git.DiffOptions{Commit1: "HEAD", Paths: []string{path}}))
 						} else { // It's a file
 							stagedChanges := status[0] != ' ' && status[0] != '?'
 							unstagedChanges := status[1] != ' '
 
-							if stagedChanges {
-								content, err = m.git.ShowDiff(git.DiffOptions{Color: true, Cached: true, Commit1: path})
+							if status == "??" {
+								rows, err = m.renderUntracked(path)
+							} else if stagedChanges {
+								content, err = m.git.ShowDiff(m.diffDisplayOptions(git.DiffOptions{Cached: true, Paths: []string{path}}))
 							} else if unstagedChanges {
-								content, err = m.git.ShowDiff(git.DiffOptions{Color: true, Commit1: path})
-							} else if status == "??" {
-								content = "Untracked file: Stage to see content as a diff."
+								content, err = m.git.ShowDiff(m.diffDisplayOptions(git.DiffOptions{Paths: []string{path}}))
 							}
 						}
 					}
 				}
 			}
+			if err == nil && rows == nil {
+				rows = m.renderDiffRows(content)
+			}
 		case BranchesPanel:
 			if m.panels[BranchesPanel].cursor < len(m.panels[BranchesPanel].lines) {
 				line := m.panels[BranchesPanel].lines[m.panels[BranchesPanel].cursor]
 				parts := strings.Split(line, "\t")
 				if len(parts) > 1 {
 					branchName := strings.TrimSpace(strings.TrimPrefix(parts[1], "(*) → "))
-					content, err = m.git.ShowLog(git.LogOptions{Graph: true, Color: "always", Branch: branchName})
+					switch m.branchMainView {
+					case branchMainBaseComparison:
+						rows, err = m.compareWithBase(branchName)
+					case branchMainMergePreview:
+						var prediction *git.MergePrediction
+						prediction, err = m.git.PredictMerge(branchName)
+						if err == nil {
+							content = m.renderMergePrediction(prediction, branchName)
+						}
+					default:
+						content, err = m.git.ShowLog(git.LogOptions{Graph: true, Color: "always", Branch: branchName})
+					}
 				}
 			}
 		case CommitsPanel:
@@ -435,7 +714,10 @@ func (m *Model) updateMainPanel() tea.Cmd {
 				parts := strings.Split(line, "\t")
 				if len(parts) >= 2 {
 					sha := parts[1]
-					content, err = m.git.ShowCommit(sha)
+					content, err = m.git.ShowCommitWithOptions(sha, m.diffDisplayOptions(git.DiffOptions{}))
+					if err == nil {
+						rows = m.renderDiffRows(content)
+					}
 				}
 			}
 		case StashPanel:
@@ -452,12 +734,62 @@ func (m *Model) updateMainPanel() tea.Cmd {
 		}
 
 		if err != nil {
-			content = "Error: " + err.Error()
+			content, rows = "Error: "+err.Error(), nil
+		}
+		if rows != nil {
+			m.limitRows(rows)
+			rows.highlightSmall()
+			content = rows.String()
 		}
 		if content == "" {
 			content = "Select an item to see details."
 		}
-		return mainContentUpdatedMsg{content: content}
+		return mainContentUpdatedMsg{content: content, rows: rows}
+	}
+}
+
+// renderFileTree renders the changed files into the Files panel, in the
+// current view mode, and puts the cursor on selectedPath, or on the collapsed
+// directory that contains it. selectedStatus picks between the rows of a
+// file listed in two sections of the grouped view.
+func (m *Model) renderFileTree(selectedPath, selectedStatus string) {
+	var renderedTree []string
+	switch m.fileView {
+	case fileViewFlat:
+		renderedTree = m.fileTree.RenderFlat()
+	case fileViewGrouped:
+		renderedTree = m.fileTree.RenderGrouped(m.theme)
+	default:
+		renderedTree = m.fileTree.Render(m.theme, m.collapsedDirs)
+	}
+	renderedTree = m.panels[FilesPanel].narrow(FilesPanel, renderedTree)
+	m.panels[FilesPanel].lines = renderedTree
+	m.panels[FilesPanel].viewport.SetContent(strings.Join(renderedTree, "\n"))
+
+	// The best match is the row of selectedPath with selectedStatus, then
+	// any row of selectedPath, then the deepest directory containing it.
+	newCursorPos, bestScore := 0, 0 // Default to top.
+	for i, line := range renderedTree {
+		parts := strings.Split(line, "\t")
+		if len(parts) < 4 || selectedPath == "" {
+			continue
+		}
+		score := 0
+		switch {
+		case parts[3] == selectedPath && parts[1] == selectedStatus:
+			score = len(selectedPath) + 2
+		case parts[3] == selectedPath:
+			score = len(selectedPath) + 1
+		case strings.HasPrefix(selectedPath, parts[3]+"/"):
+			score = len(parts[3])
+		}
+		if score > bestScore {
+			newCursorPos, bestScore = i, score
+		}
+	}
+	m.panels[FilesPanel].cursor = newCursorPos
+	if vp := &m.panels[FilesPanel].viewport; newCursorPos < vp.YOffset {
+		vp.SetYOffset(newCursorPos)
 	}
 }
 
@@ -470,7 +802,8 @@ func (m Model) handleWindowSizeMsg(msg tea.WindowSizeMsg) (Model, tea.Cmd) {
 	m.helpViewport.Height = int(float64(m.height) * helpViewHeightRatio)
 
 	m = m.recalculateLayout()
-	return m, nil
+	// Diffs are laid out for the width of the Main panel.
+	return m, m.updateMainPanel()
 }
 
 // handleMouseMsg handles all mouse events, including clicks and scrolling.
@@ -536,6 +869,10 @@ func (m Model) handleMouseMsg(msg tea.MouseMsg) (Model, tea.Cmd) {
 // handlePanelKeys handles keybindings that are specific to the focused panel.
 func (m *Model) handlePanelKeys(msg tea.KeyMsg) tea.Cmd {
 	switch m.focusedPanel {
+	case MainPanel:
+		return m.handleMainPanelKeys(msg)
+	case StatusPanel:
+		return m.handleStatusPanelKeys(msg)
 	case FilesPanel:
 		return m.handleFilesPanelKeys(msg)
 	case BranchesPanel:
@@ -572,16 +909,122 @@ func (m *Model) handleCursorMovement(msg tea.KeyMsg) (bool, tea.Cmd) {
 		}
 	}
 	if itemSelected {
+		m.mainRowLimit = 0
 		m.panels[MainPanel].viewport.GotoTop()
 		return true, m.updateMainPanel()
 	}
 	return false, nil
 }
 
+// selectionDone returns a command that clears the selection of a panel once
+// a bulk action on it was confirmed.
+func selectionDone(panel Panel) tea.Cmd {
+	return func() tea.Msg { return selectionDoneMsg{panel} }
+}
+
+// handleListKeys handles the keys shared by the list panels: toggling lines
+// into the selection that bulk actions work on, and starting to type a
+// filter. It returns true if the key was handled.
+func (m *Model) handleListKeys(msg tea.KeyMsg) bool {
+	p := &m.panels[m.focusedPanel]
+	switch {
+	case key.Matches(msg, keys.ToggleSelection):
+		p.toggleMark(m.focusedPanel)
+	case key.Matches(msg, keys.RangeSelection):
+		p.toggleRange(m.focusedPanel)
+	case key.Matches(msg, keys.Filter):
+		m.mode = modeFilter
+	default:
+		return false
+	}
+	return true
+}
+
+// handleMainPanelKeys handles the diff view toggles of the Main panel. The
+// whitespace, context, rename and algorithm settings apply to the diffs of
+// files and commits.
+func (m *Model) handleMainPanelKeys(msg tea.KeyMsg) tea.Cmd {
+	if m.comparison != nil {
+		if handled, cmd := m.handleComparisonKeys(msg); handled {
+			return cmd
+		}
+	}
+	switch {
+	case key.Matches(msg, keys.ToggleSplitDiff):
+		m.diffSplit = !m.diffSplit
+	case key.Matches(msg, keys.ToggleIgnoreAllSpace):
+		m.diffOptions.IgnoreAllSpace = !m.diffOptions.IgnoreAllSpace
+	case key.Matches(msg, keys.ToggleIgnoreSpaceChange):
+		m.diffOptions.IgnoreSpaceChange = !m.diffOptions.IgnoreSpaceChange
+	case key.Matches(msg, keys.ToggleIgnoreBlankLines):
+		m.diffOptions.IgnoreBlankLines = !m.diffOptions.IgnoreBlankLines
+	case key.Matches(msg, keys.IncreaseContext):
+		m.diffOptions.Context = diffContext(m.diffOptions) + 1
+	case key.Matches(msg, keys.DecreaseContext):
+		m.diffOptions.Context = max(diffContext(m.diffOptions)-1, 1)
+	case key.Matches(msg, keys.CycleRenameThreshold):
+		m.diffOptions.FindRenames = nextInCycle(renameThresholds, m.diffOptions.FindRenames)
+	case key.Matches(msg, keys.CycleDiffAlgorithm):
+		m.diffOptions.Algorithm = nextInCycle(diffAlgorithms, m.diffOptions.Algorithm)
+	case key.Matches(msg, keys.LoadMoreRows):
+		m.mainRowLimit = max(m.mainRowLimit, diffMaxRows) + diffLoadMoreRows
+	default:
+		return nil
+	}
+	return m.updateMainPanel()
+}
+
+// handleStatusPanelKeys binds continue, skip and abort while an operation
+// such as a merge or rebase is in progress.
+func (m *Model) handleStatusPanelKeys(msg tea.KeyMsg) tea.Cmd {
+	state := m.repoState
+	var run func() (string, error)
+	switch {
+	case key.Matches(msg, keys.ContinueOperation) && state.CanContinue():
+		run = m.git.ContinueOperation
+	case key.Matches(msg, keys.SkipOperation) && state.CanSkip():
+		run = m.git.SkipOperation
+	case key.Matches(msg, keys.AbortOperation) && state.InProgress():
+		m.mode = modeConfirm
+		m.confirmMessage = fmt.Sprintf("Abort %s? The repository returns to where it was before.", state)
+		m.confirmCallback = func(confirmed bool) tea.Cmd {
+			m.mode = modeNormal
+			if !confirmed {
+				return nil
+			}
+			return m.runRepoOperation(m.git.AbortOperation)
+		}
+		return nil
+	default:
+		return nil
+	}
+	return m.runRepoOperation(run)
+}
+
+// runRepoOperation returns a command that continues, skips or aborts the
+// in-progress operation and refreshes every panel it may have changed.
+func (m *Model) runRepoOperation(run func() (string, error)) tea.Cmd {
+	return func() tea.Msg {
+		_, err := run()
+		if err != nil {
+			return errMsg{err}
+		}
+		return tea.Batch(
+			m.fetchPanelContent(StatusPanel),
+			m.fetchPanelContent(FilesPanel),
+			m.fetchPanelContent(BranchesPanel),
+			m.fetchPanelContent(CommitsPanel),
+		)
+	}
+}
+
 func (m *Model) handleFilesPanelKeys(msg tea.KeyMsg) tea.Cmd {
 	if handled, cmd := m.handleCursorMovement(msg); handled {
 		return cmd
 	}
+	if m.handleListKeys(msg) {
+		return nil
+	}
 
 	if m.panels[FilesPanel].cursor >= len(m.panels[FilesPanel].lines) {
 		return nil
@@ -595,17 +1038,41 @@ func (m *Model) handleFilesPanelKeys(msg tea.KeyMsg) tea.Cmd {
 	filePath := parts[3]
 
 	switch {
+	case key.Matches(msg, keys.ToggleDirectory):
+		if status != "" || filePath == "." || filePath == "" {
+			return nil // Only directories below the root can be collapsed.
+		}
+		if m.collapsedDirs == nil {
+			m.collapsedDirs = make(map[string]bool)
+		}
+		if m.collapsedDirs[filePath] {
+			delete(m.collapsedDirs, filePath)
+		} else {
+			m.collapsedDirs[filePath] = true
+		}
+		m.renderFileTree(filePath, status)
+
+	case key.Matches(msg, keys.CollapseAllDirectories):
+		m.collapsedDirs = make(map[string]bool)
+		for _, path := range m.fileTree.directoryPaths() {
+			m.collapsedDirs[path] = true
+		}
+		m.renderFileTree(filePath, status)
+		return m.updateMainPanel()
+
+	case key.Matches(msg, keys.ExpandAllDirectories):
+		m.collapsedDirs = nil
+		m.renderFileTree(filePath, status)
+
+	case key.Matches(msg, keys.CycleFileView):
+		m.fileView = nextInCycle([]fileViewMode{fileViewTree, fileViewFlat, fileViewGrouped}, m.fileView)
+		m.renderFileTree(filePath, status)
+		return m.updateMainPanel()
+
 	case key.Matches(msg, keys.Commit):
-		m.mode = modeCommit
-		m.textInput.SetValue("")
-		m.descriptionInput.SetValue("")
-		m.textInput.Focus()
-		m.commitCallback = func(title, description string) tea.Cmd {
+		m.openCommitPopup("", func(title, description string) tea.Cmd {
 			return func() tea.Msg {
-				commitMsg := title
-				if description != "" {
-					commitMsg = title + "\n\n" + description
-				}
+				commitMsg := buildCommitMessage(title, description)
 				_, err := m.git.Commit(git.CommitOptions{Message: commitMsg})
 				if err != nil {
 					return errMsg{err}
@@ -616,9 +1083,44 @@ func (m *Model) handleFilesPanelKeys(msg tea.KeyMsg) tea.Cmd {
 					m.fetchPanelContent(SecondaryPanel),
 				)
 			}
+		})
+
+	case key.Matches(msg, keys.Absorb):
+		plan, err := m.git.PlanAbsorb()
+		if err != nil {
+			return func() tea.Msg { return errMsg{err} }
+		}
+		m.mode = modeAbsorb
+		m.absorbReview = newAbsorbReview(plan)
+
+	case key.Matches(msg, keys.AmendNoEdit):
+		m.mode = modeConfirm
+		m.confirmMessage = "Amend the last commit with the staged changes, keeping its message?"
+		m.confirmCallback = func(confirmed bool) tea.Cmd {
+			m.mode = modeNormal
+			if !confirmed {
+				return nil
+			}
+			return func() tea.Msg {
+				_, err := m.git.Commit(git.CommitOptions{Amend: true})
+				if err != nil {
+					return errMsg{err}
+				}
+				return tea.Batch(
+					m.fetchPanelContent(FilesPanel),
+					m.fetchPanelContent(CommitsPanel),
+				)
+			}
 		}
 
 	case key.Matches(msg, keys.StageItem):
+		if paths := m.panels[FilesPanel].selection(FilesPanel); len(paths) > 0 {
+			m.panels[FilesPanel].clearSelection()
+			return m.stagePaths(paths)
+		}
+		if status == "" {
+			return m.stagePaths([]string{filePath})
+		}
 		// If the item is unstaged, stage it, and vice-versa.
 		if status[0] == ' ' || status[0] == '?' {
 			_, err := m.git.AddFiles([]string{filePath})
@@ -641,23 +1143,49 @@ func (m *Model) handleFilesPanelKeys(msg tea.KeyMsg) tea.Cmd {
 		return m.fetchPanelContent(FilesPanel)
 
 	case key.Matches(msg, keys.Discard):
+		selected := m.panels[FilesPanel].selection(FilesPanel)
+		paths := selected
+		if len(paths) == 0 {
+			paths = []string{filePath}
+		}
+		var nodes []*Node
+		for _, path := range paths {
+			if node := m.fileTree.find(path); node != nil {
+				nodes = append(nodes, node)
+			}
+		}
+		if len(nodes) == 0 {
+			return nil
+		}
+		if len(selected) == 0 && len(status) == 2 && status[1] == ' ' {
+			// The row only has staged changes, like the rows of the Staged
+			// section of the grouped view, and those are never discarded.
+			return nil
+		}
 		m.mode = modeConfirm
-		m.confirmMessage = fmt.Sprintf("Discard changes to %s?", filePath)
+		switch {
+		case len(selected) > 0:
+			m.confirmMessage = m.renderPreviewList(fmt.Sprintf("Discard all unstaged changes of %d selected item(s)? Their untracked files are deleted.", len(nodes)), selected, m.theme.NormalText)
+		case status == "":
+			m.confirmMessage = fmt.Sprintf("Discard all unstaged changes in %s? Its untracked files are deleted.", filePath)
+		case status == "??":
+			m.confirmMessage = fmt.Sprintf("Delete the untracked %s?", filePath)
+		case status[0] != ' ':
+			m.confirmMessage = fmt.Sprintf("Discard the unstaged changes to %s? Its staged changes are kept.", filePath)
+		default:
+			m.confirmMessage = fmt.Sprintf("Discard changes to %s?", filePath)
+		}
 		m.confirmCallback = func(confirmed bool) tea.Cmd {
 			m.mode = modeNormal
 			if !confirmed {
 				return nil
 			}
-			return func() tea.Msg {
-				_, err := m.git.Restore(git.RestoreOptions{
-					Paths:      []string{filePath},
-					WorkingDir: true,
-				})
-				if err != nil {
+			return tea.Batch(selectionDone(FilesPanel), func() tea.Msg {
+				if err := m.discardChanges(nodes...); err != nil {
 					return errMsg{err}
 				}
 				return m.fetchPanelContent(FilesPanel)
-			}
+			})
 		}
 
 	case key.Matches(msg, keys.StashAll):
@@ -673,10 +1201,71 @@ func (m *Model) handleFilesPanelKeys(msg tea.KeyMsg) tea.Cmd {
 	return nil
 }
 
+// stagePaths stages all changes of files and of everything below
+// directories, or unstages them when all of them are staged already.
+func (m *Model) stagePaths(paths []string) tea.Cmd {
+	allStaged := true
+	for _, path := range paths {
+		node := m.fileTree.find(path)
+		if node == nil {
+			return nil
+		}
+		if node.stagingState() != stagingFull {
+			allStaged = false
+		}
+	}
+	var err error
+	if allStaged {
+		_, err = m.git.ResetFiles(paths)
+	} else {
+		_, err = m.git.AddFiles(paths)
+	}
+	if err != nil {
+		return func() tea.Msg { return errMsg{err} }
+	}
+	return m.fetchPanelContent(FilesPanel)
+}
+
+// discardChanges discards the unstaged changes of files, or of all files
+// below directories: tracked files are restored from the index and untracked
+// ones deleted. Staged changes and files with conflicts are kept.
+func (m *Model) discardChanges(nodes ...*Node) error {
+	var tracked, untracked []string
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		node.walkFiles(func(file *Node) {
+			if seen[file.path] {
+				return
+			}
+			seen[file.path] = true
+			switch {
+			case file.status == "??":
+				untracked = append(untracked, file.path)
+			case len(file.status) == 2 && file.status[1] != ' ' && !isConflicted(file.status):
+				tracked = append(tracked, file.path)
+			}
+		})
+	}
+	if len(tracked) > 0 {
+		if _, err := m.git.Restore(git.RestoreOptions{Paths: tracked, WorkingDir: true}); err != nil {
+			return err
+		}
+	}
+	if len(untracked) > 0 {
+		if _, err := m.git.CleanFiles(untracked); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *Model) handleBranchesPanelKeys(msg tea.KeyMsg) tea.Cmd {
 	if handled, cmd := m.handleCursorMovement(msg); handled {
 		return cmd
 	}
+	if m.handleListKeys(msg) {
+		return nil
+	}
 
 	if m.panels[BranchesPanel].cursor >= len(m.panels[BranchesPanel].lines) {
 		return nil
@@ -730,20 +1319,97 @@ func (m *Model) handleBranchesPanelKeys(msg tea.KeyMsg) tea.Cmd {
 		}
 
 	case key.Matches(msg, keys.DeleteBranch):
+		branches := m.panels[BranchesPanel].selection(BranchesPanel)
 		m.mode = modeConfirm
-		m.confirmMessage = fmt.Sprintf("Delete branch %s?", branchName)
+		if len(branches) > 0 {
+			m.confirmMessage = m.renderPreviewList(fmt.Sprintf("Delete %d selected branch(es)?", len(branches)), branches, m.theme.NormalText)
+		} else {
+			branches = []string{branchName}
+			m.confirmMessage = fmt.Sprintf("Delete branch %s?", branchName)
+		}
 		m.confirmCallback = func(confirmed bool) tea.Cmd {
 			m.mode = modeNormal
 			if !confirmed {
 				return nil
 			}
-			return func() tea.Msg {
-				_, err := m.git.ManageBranch(git.BranchOptions{Delete: true, Name: branchName})
-				if err != nil {
-					return errMsg{err}
+			return tea.Batch(selectionDone(BranchesPanel), func() tea.Msg {
+				for _, branch := range branches {
+					_, err := m.git.ManageBranch(git.BranchOptions{Delete: true, Name: branch})
+					if err != nil {
+						return errMsg{err}
+					}
 				}
 				return m.fetchPanelContent(BranchesPanel)
+			})
+		}
+
+	case key.Matches(msg, keys.DiffBranch):
+		// Compare the marked commit, or HEAD, with the branch.
+		from := "HEAD"
+		if m.commitMark != "" {
+			from = m.commitMark
+			m.commitMark = ""
+		}
+		return m.startComparison(from, branchName)
+
+	case key.Matches(msg, keys.PredictMerge):
+		m.toggleBranchMainView(branchMainMergePreview)
+		return m.updateMainPanel()
+
+	case key.Matches(msg, keys.CompareWithBase):
+		m.toggleBranchMainView(branchMainBaseComparison)
+		return m.updateMainPanel()
+
+	case key.Matches(msg, keys.MergeBranch):
+		if strings.HasPrefix(parts[1], "(*)") {
+			return nil
+		}
+		incoming, err := m.git.CommitsInRange("HEAD.." + branchName)
+		if err != nil {
+			return func() tea.Msg { return errMsg{err} }
+		}
+		preview := m.renderPreviewList(fmt.Sprintf("%d incoming commit(s) from %s:", len(incoming), branchName), incoming, m.theme.NormalText)
+		if len(incoming) == 0 {
+			preview = m.theme.NormalText.Render(fmt.Sprintf("Already up to date with %s.", branchName))
+		}
+		merge := func(options git.MergeOptions) func(m *Model) tea.Cmd {
+			options.BranchName = branchName
+			return func(m *Model) tea.Cmd {
+				return m.integrateBranch(func() (string, error) { return m.git.Merge(options) })
+			}
+		}
+		m.openMenu(fmt.Sprintf("Merge %s into the current branch", branchName), []menuItem{
+			{label: "Fast-forward only", detail: preview, action: merge(git.MergeOptions{FastForwardOnly: true})},
+			{label: "Merge commit (--no-ff)", detail: preview, action: merge(git.MergeOptions{NoFastForward: true})},
+			{label: "Squash merge (stage the changes, commit yourself)", detail: preview, action: merge(git.MergeOptions{Squash: true})},
+		})
+
+	case key.Matches(msg, keys.RebaseBranch):
+		if strings.HasPrefix(parts[1], "(*)") {
+			return nil
+		}
+		incoming, err := m.git.CommitsInRange("HEAD.." + branchName)
+		if err != nil {
+			return func() tea.Msg { return errMsg{err} }
+		}
+		replayed, err := m.git.CommitsInRange(branchName + "..HEAD")
+		if err != nil {
+			return func() tea.Msg { return errMsg{err} }
+		}
+		m.mode = modeConfirm
+		m.confirmMessage = strings.Join([]string{
+			fmt.Sprintf("Rebase the current branch onto %s?", branchName),
+			m.renderPreviewList(fmt.Sprintf("%d incoming commit(s):", len(incoming)), incoming, m.theme.NormalText),
+			m.renderPreviewList(fmt.Sprintf("%d commit(s) will be replayed on top:", len(replayed)), replayed, m.theme.NormalText),
+		}, "\n")
+		m.confirmCallback = func(confirmed bool) tea.Cmd {
+			m.mode = modeNormal
+			if !confirmed {
+				return nil
 			}
+			return m.integrateBranch(func() (string, error) {
+				return m.git.Rebase(git.RebaseOptions{BranchName: branchName})
+			})
 		}
 
 	case key.Matches(msg, keys.RenameBranch):
@@ -771,10 +1437,41 @@ func (m *Model) handleBranchesPanelKeys(msg tea.KeyMsg) tea.Cmd {
 	return nil
 }
 
+// integrateBranch returns a command that runs a merge or rebase. Conflicts
+// leave the operation in progress, to be continued from the Status panel.
+func (m *Model) integrateBranch(run func() (string, error)) tea.Cmd {
+	return func() tea.Msg {
+		_, err := run()
+		if err != nil {
+			return errMsg{err}
+		}
+		return tea.Batch(
+			m.fetchPanelContent(StatusPanel),
+			m.fetchPanelContent(FilesPanel),
+			m.fetchPanelContent(BranchesPanel),
+			m.fetchPanelContent(CommitsPanel),
+		)
+	}
+}
+
+// toggleBranchMainView switches the Main panel of the Branches panel between
+// view and the log.
+func (m *Model) toggleBranchMainView(view branchMainView) {
+	if m.branchMainView == view {
+		m.branchMainView = branchMainLog
+	} else {
+		m.branchMainView = view
+	}
+	m.panels[MainPanel].viewport.GotoTop()
+}
+
 func (m *Model) handleCommitsPanelKeys(msg tea.KeyMsg) tea.Cmd {
 	if handled, cmd := m.handleCursorMovement(msg); handled {
 		return cmd
 	}
+	if m.handleListKeys(msg) {
+		return nil
+	}
 
 	if m.panels[CommitsPanel].cursor >= len(m.panels[CommitsPanel].lines) {
 		return nil
@@ -788,16 +1485,14 @@ func (m *Model) handleCommitsPanelKeys(msg tea.KeyMsg) tea.Cmd {
 
 	switch {
 	case key.Matches(msg, keys.AmendCommit):
-		m.mode = modeCommit
-		m.textInput.SetValue("")
-		m.descriptionInput.SetValue("")
-		m.textInput.Focus()
-		m.commitCallback = func(title, description string) tea.Cmd {
+		// Start from the existing message so amending never loses it.
+		headMessage, err := m.git.GetCommitMessage("HEAD")
+		if err != nil {
+			return func() tea.Msg { return errMsg{err} }
+		}
+		m.openCommitPopup(headMessage, func(title, description string) tea.Cmd {
 			return func() tea.Msg {
-				commitMsg := title
-				if description != "" {
-					commitMsg = title + "\n\n" + description
-				}
+				commitMsg := buildCommitMessage(title, description)
 				_, err := m.git.Commit(git.CommitOptions{Message: commitMsg, Amend: true})
 				if err != nil {
 					return errMsg{err}
@@ -808,18 +1503,65 @@ func (m *Model) handleCommitsPanelKeys(msg tea.KeyMsg) tea.Cmd {
 					m.fetchPanelContent(SecondaryPanel),
 				)
 			}
+		})
+
+	case key.Matches(msg, keys.RewordCommit):
+		message, err := m.git.GetCommitMessage(sha)
+		if err != nil {
+			return func() tea.Msg { return errMsg{err} }
 		}
+		m.openCommitPopup(message, func(title, description string) tea.Cmd {
+			return func() tea.Msg {
+				_, err := m.git.RewordCommit(sha, buildCommitMessage(title, description))
+				if err != nil {
+					return errMsg{err}
+				}
+				return tea.Batch(
+					m.fetchPanelContent(CommitsPanel),
+					m.fetchPanelContent(FilesPanel),
+				)
+			}
+		})
+
+	case key.Matches(msg, keys.CreateFixup):
+		warning := m.pushedCommitWarning(sha)
+		m.openMenu(fmt.Sprintf("Create fixup commit for %s from staged changes", sha), []menuItem{
+			{
+				label:  "fixup! (fold in, keep the target's message)",
+				detail: warning,
+				action: func(m *Model) tea.Cmd {
+					return m.createFixupCommit(git.CommitOptions{Fixup: sha})
+				},
+			},
+			{
+				label:  "squash! (fold in, add to the target's message)",
+				detail: warning,
+				action: func(m *Model) tea.Cmd {
+					m.mode = modeInput
+					m.promptTitle = "Message to add when squashing (optional)"
+					m.textInput.SetValue("")
+					m.textInput.Focus()
+					m.inputCallback = func(input string) tea.Cmd {
+						return m.createFixupCommit(git.CommitOptions{Squash: sha, Message: input})
+					}
+					return nil
+				},
+			},
+		})
 
-	case key.Matches(msg, keys.Revert):
+	case key.Matches(msg, keys.Autosquash):
 		m.mode = modeConfirm
-		m.confirmMessage = fmt.Sprintf("Revert commit %s?", sha)
+		m.confirmMessage = fmt.Sprintf("Fold all fixup!/squash! commits above %s into their targets?", sha)
+		if warning := m.pushedCommitWarning(sha); warning != "" {
+			m.confirmMessage += "\n" + warning
+		}
 		m.confirmCallback = func(confirmed bool) tea.Cmd {
 			m.mode = modeNormal
 			if !confirmed {
 				return nil
 			}
 			return func() tea.Msg {
-				_, err := m.git.Revert(sha)
+				_, err := m.git.Autosquash(sha)
 				if err != nil {
 					return errMsg{err}
 				}
@@ -830,16 +1572,114 @@ func (m *Model) handleCommitsPanelKeys(msg tea.KeyMsg) tea.Cmd {
 			}
 		}
 
-	case key.Matches(msg, keys.ResetToCommit):
+	case key.Matches(msg, keys.SquashCommit):
+		m.confirmHistoryRewrite(fmt.Sprintf("Squash %s into its parent, combining their messages?", sha), sha, m.rewriteHistory(sha, m.git.SquashCommit))
+
+	case key.Matches(msg, keys.DropCommit):
+		m.confirmHistoryRewrite(fmt.Sprintf("Drop commit %s? Its changes will be removed from the branch.", sha), sha, m.rewriteHistory(sha, m.git.DropCommit))
+
+	case key.Matches(msg, keys.MoveCommitUp):
+		m.confirmHistoryRewrite(fmt.Sprintf("Move %s above its child?", sha), sha, m.moveCommit(sha, m.git.MoveCommitUp, -1))
+
+	case key.Matches(msg, keys.MoveCommitDown):
+		m.confirmHistoryRewrite(fmt.Sprintf("Move %s below its parent?", sha), sha, m.moveCommit(sha, m.git.MoveCommitDown, 1))
+
+	case key.Matches(msg, keys.SplitCommit):
+		m.confirmHistoryRewrite(fmt.Sprintf("Split %s? Its changes will be unstaged so you can commit them in pieces, then continue the rebase.", sha), sha, m.rewriteHistory(sha, m.git.SplitCommit))
+
+	case key.Matches(msg, keys.DiffMarked):
+		// Compare the marked commit with the selected one, or the selected
+		// commit with HEAD when nothing is marked.
+		if m.commitMark != "" && m.commitMark != sha {
+			from := m.commitMark
+			m.commitMark = ""
+			return m.startComparison(from, sha)
+		}
+		return m.startComparison(sha, "HEAD")
+
+	case key.Matches(msg, keys.MarkCommit):
+		if m.commitMark == sha {
+			m.commitMark = ""
+		} else {
+			m.commitMark = sha
+		}
+
+	case key.Matches(msg, keys.Revert):
+		selected := m.panels[CommitsPanel].selection(CommitsPanel)
+		if len(selected) > 0 && m.commitMark != "" {
+			// Neither is obviously meant, so don't guess which commits to revert.
+			return func() tea.Msg {
+				return errMsg{fmt.Errorf("select commits or mark a range to revert, not both")}
+			}
+		}
+		if len(selected) > 1 {
+			m.openRevertMenu(selected)
+			return nil
+		}
+		if len(selected) == 1 {
+			sha = selected[0]
+		}
+		if m.commitMark != "" && m.commitMark != sha {
+			commits, err := m.git.CommitsBetween(m.commitMark, sha)
+			if err != nil {
+				return func() tea.Msg { return errMsg{err} }
+			}
+			m.commitMark = ""
+			m.openRevertMenu(commits)
+			return nil
+		}
+
+		parents, err := m.git.CommitParents(sha)
+		if err != nil {
+			return func() tea.Msg { return errMsg{err} }
+		}
+		if len(parents) > 1 {
+			var items []menuItem
+			for i, parent := range parents {
+				mainline := i + 1
+				label := fmt.Sprintf("Parent %d: %s", mainline, parent)
+				if mainline == 1 {
+					label += " (the branch merged into)"
+				}
+				items = append(items, menuItem{
+					label: label,
+					action: func(m *Model) tea.Cmd {
+						m.panels[CommitsPanel].clearSelection()
+						return m.revertCommits(git.RevertOptions{Commits: []string{sha}, Mainline: mainline})
+					},
+				})
+			}
+			m.openMenu(fmt.Sprintf("Revert merge %s relative to which parent?", sha), items)
+			return nil
+		}
+
 		m.mode = modeConfirm
-		m.confirmMessage = fmt.Sprintf("Hard reset to commit %s? This will discard all changes!", sha)
+		m.confirmMessage = fmt.Sprintf("Revert commit %s?", sha)
 		m.confirmCallback = func(confirmed bool) tea.Cmd {
 			m.mode = modeNormal
 			if !confirmed {
 				return nil
 			}
-			return func() tea.Msg {
-				_, err := m.git.ResetToCommit(sha)
+			return tea.Batch(selectionDone(CommitsPanel), m.revertCommits(git.RevertOptions{Commits: []string{sha}}))
+		}
+
+	case key.Matches(msg, keys.CherryPick):
+		commits := m.panels[CommitsPanel].selection(CommitsPanel)
+		if len(commits) == 0 {
+			commits = []string{sha}
+		}
+		m.mode = modeConfirm
+		m.confirmMessage = m.renderPreviewList(fmt.Sprintf("Cherry-pick %d commit(s) onto the current branch?", len(commits)), m.commitSubjects(commits), m.theme.NormalText)
+		m.confirmCallback = func(confirmed bool) tea.Cmd {
+			m.mode = modeNormal
+			if !confirmed {
+				return nil
+			}
+			// The panel lists the newest commits first; apply the oldest first.
+			oldestFirst := slices.Clone(commits)
+			slices.Reverse(oldestFirst)
+			return tea.Batch(selectionDone(CommitsPanel), func() tea.Msg {
+				_, err := m.git.CherryPick(oldestFirst)
 				if err != nil {
 					return errMsg{err}
 				}
@@ -848,16 +1688,197 @@ func (m *Model) handleCommitsPanelKeys(msg tea.KeyMsg) tea.Cmd {
 					m.fetchPanelContent(FilesPanel),
 					m.fetchPanelContent(StatusPanel),
 				)
-			}
+			})
+		}
+
+	case key.Matches(msg, keys.ResetToCommit):
+		preview, err := m.git.PreviewReset(sha)
+		if err != nil {
+			return func() tea.Msg { return errMsg{err} }
 		}
+		var items []menuItem
+		for _, mode := range []git.ResetMode{git.ResetSoft, git.ResetMixed, git.ResetHard, git.ResetKeep} {
+			mode := mode
+			items = append(items, menuItem{
+				label:  resetModeLabels[mode],
+				detail: m.renderResetPreview(preview, mode, sha),
+				action: func(m *Model) tea.Cmd {
+					reset := func() tea.Msg {
+						_, err := m.git.ResetToCommit(sha, mode)
+						if err != nil {
+							return errMsg{err}
+						}
+						return tea.Batch(
+							m.fetchPanelContent(CommitsPanel),
+							m.fetchPanelContent(FilesPanel),
+							m.fetchPanelContent(StatusPanel),
+						)
+					}
+					if mode == git.ResetSoft || mode == git.ResetMixed {
+						return reset
+					}
+					// Hard and keep resets change the working tree, so they
+					// are confirmed once more with the preview in view.
+					m.mode = modeConfirm
+					m.confirmMessage = fmt.Sprintf("Reset to commit %s with --%s?\n\n%s", sha, mode, m.renderResetPreview(preview, mode, sha))
+					m.confirmCallback = func(confirmed bool) tea.Cmd {
+						m.mode = modeNormal
+						if !confirmed {
+							return nil
+						}
+						return reset
+					}
+					return nil
+				},
+			})
+		}
+		m.openMenu(fmt.Sprintf("Reset current branch to %s", sha), items)
 	}
 	return nil
 }
 
+// confirmHistoryRewrite asks for confirmation, warning if the commit is
+// already pushed, before running the rewrite command.
+func (m *Model) confirmHistoryRewrite(message, sha string, rewrite tea.Cmd) {
+	m.mode = modeConfirm
+	m.confirmMessage = message
+	if warning := m.pushedCommitWarning(sha); warning != "" {
+		m.confirmMessage += "\n" + warning
+	}
+	m.confirmCallback = func(confirmed bool) tea.Cmd {
+		m.mode = modeNormal
+		if !confirmed {
+			return nil
+		}
+		return rewrite
+	}
+}
+
+// rewriteHistory returns a command that runs a history rewrite on a commit
+// and refreshes the panels it affects.
+func (m *Model) rewriteHistory(sha string, rewrite func(sha string) (string, error)) tea.Cmd {
+	return func() tea.Msg {
+		_, err := rewrite(sha)
+		if err != nil {
+			return errMsg{err}
+		}
+		return tea.Batch(
+			m.fetchPanelContent(CommitsPanel),
+			m.fetchPanelContent(FilesPanel),
+			m.fetchPanelContent(StatusPanel),
+		)
+	}
+}
+
+// moveCommit returns a command that moves a commit by offset commits, one
+// towards HEAD when negative, and keeps the cursor on it in the Commits panel.
+func (m *Model) moveCommit(sha string, move func(sha string) (string, error), offset int) tea.Cmd {
+	return func() tea.Msg {
+		// The moved commit gets a new SHA, so find it by its distance from
+		// HEAD instead, which the move changes by offset.
+		newer, err := m.git.CommitsInRange(sha + "..HEAD")
+		if err != nil {
+			return errMsg{err}
+		}
+		if _, err := move(sha); err != nil {
+			return errMsg{err}
+		}
+		moved, err := m.git.ResolveCommit(fmt.Sprintf("HEAD~%d", len(newer)+offset))
+		if err != nil {
+			return errMsg{err}
+		}
+		return tea.Batch(
+			m.fetchCommitsSelecting(moved),
+			m.fetchPanelContent(FilesPanel),
+			m.fetchPanelContent(StatusPanel),
+		)
+	}
+}
+
+// openRevertMenu asks whether several commits, newest first, are reverted
+// in a single commit or one commit each.
+func (m *Model) openRevertMenu(commits []string) {
+	m.openMenu(fmt.Sprintf("Revert %d commits", len(commits)), []menuItem{
+		{
+			label: "Revert as a single commit",
+			action: func(m *Model) tea.Cmd {
+				m.panels[CommitsPanel].clearSelection()
+				return m.revertCommits(git.RevertOptions{Commits: commits, Squash: true})
+			},
+		},
+		{
+			label: "Revert as one commit per reverted commit",
+			action: func(m *Model) tea.Cmd {
+				m.panels[CommitsPanel].clearSelection()
+				return m.revertCommits(git.RevertOptions{Commits: commits})
+			},
+		},
+	})
+}
+
+// commitSubjects returns the commits of the Commits panel with their
+// subjects, for listing them in a pop-up.
+func (m *Model) commitSubjects(commits []string) []string {
+	subjects := make(map[string]string)
+	for _, line := range m.panels[CommitsPanel].lines {
+		parts := strings.Split(line, "\t")
+		if len(parts) >= 4 {
+			subjects[parts[1]] = parts[3]
+		}
+	}
+	var items []string
+	for _, sha := range commits {
+		items = append(items, strings.TrimSpace(sha+" "+subjects[sha]))
+	}
+	return items
+}
+
+// revertCommits returns a command that reverts commits and refreshes the
+// panels. Conflicts leave the revert in progress.
+func (m *Model) revertCommits(options git.RevertOptions) tea.Cmd {
+	return func() tea.Msg {
+		_, err := m.git.Revert(options)
+		if err != nil {
+			return errMsg{err}
+		}
+		return tea.Batch(
+			m.fetchPanelContent(CommitsPanel),
+			m.fetchPanelContent(FilesPanel),
+		)
+	}
+}
+
+// createFixupCommit returns a command that commits the staged changes as a
+// fixup! or squash! commit.
+func (m *Model) createFixupCommit(options git.CommitOptions) tea.Cmd {
+	return func() tea.Msg {
+		_, err := m.git.Commit(options)
+		if err != nil {
+			return errMsg{err}
+		}
+		return tea.Batch(
+			m.fetchPanelContent(FilesPanel),
+			m.fetchPanelContent(CommitsPanel),
+		)
+	}
+}
+
+// pushedCommitWarning returns a styled warning if rewriting the given commit
+// would change history that is already in the upstream branch.
+func (m *Model) pushedCommitWarning(sha string) string {
+	if !m.git.IsCommitPushed(sha) {
+		return ""
+	}
+	return m.theme.LintViolation.Render(fmt.Sprintf("⚠ %s is already pushed to the upstream; rewriting it will require a force push.", sha))
+}
+
 func (m *Model) handleStashPanelKeys(msg tea.KeyMsg) tea.Cmd {
 	if handled, cmd := m.handleCursorMovement(msg); handled {
 		return cmd
 	}
+	if m.handleListKeys(msg) {
+		return nil
+	}
 
 	if m.panels[StashPanel].cursor >= len(m.panels[StashPanel].lines) {
 		return nil
@@ -891,24 +1912,49 @@ func (m *Model) handleStashPanelKeys(msg tea.KeyMsg) tea.Cmd {
 		)
 
 	case key.Matches(msg, keys.StashDrop):
+		p := m.panels[StashPanel]
+		selected := p.selection(StashPanel)
+		if len(selected) > 0 {
+			var items []string
+			for _, i := range p.selectedLines(StashPanel) {
+				items = append(items, plainLine(StashPanel, p.lines[i]))
+			}
+			m.confirmMessage = m.renderPreviewList(fmt.Sprintf("Drop %d selected stash(es)?", len(selected)), items, m.theme.NormalText)
+		} else if sha := selectionKey(StashPanel, line); sha != "" {
+			selected = []string{sha}
+			m.confirmMessage = fmt.Sprintf("Drop stash %s?", stashID)
+		} else {
+			return nil
+		}
 		m.mode = modeConfirm
-		m.confirmMessage = fmt.Sprintf("Drop stash %s?", stashID)
 		m.confirmCallback = func(confirmed bool) tea.Cmd {
 			m.mode = modeNormal
 			if !confirmed {
 				return nil
 			}
-			return func() tea.Msg {
-				_, err := m.git.Stash(git.StashOptions{Drop: true, StashID: stashID})
+			return tea.Batch(selectionDone(StashPanel), func() tea.Msg {
+				// The selection holds SHAs, as stash names change whenever a
+				// stash is pushed or dropped. Look up their current names and
+				// drop the oldest first, as dropping one renumbers the
+				// stashes older than it.
+				stashList, err := m.git.GetStashes()
 				if err != nil {
 					return errMsg{err}
 				}
+				for i := len(stashList) - 1; i >= 0; i-- {
+					if !slices.Contains(selected, stashList[i].SHA) {
+						continue
+					}
+					if _, err := m.git.Stash(git.StashOptions{Drop: true, StashID: stashList[i].Name}); err != nil {
+						return errMsg{err}
+					}
+				}
 				// Reset cursor if we deleted the last item
 				if m.panels[StashPanel].cursor >= len(m.panels[StashPanel].lines)-1 && m.panels[StashPanel].cursor > 0 {
 					m.panels[StashPanel].cursor--
 				}
 				return m.fetchPanelContent(StashPanel)
-			}
+			})
 		}
 	}
 	return nil
@@ -959,6 +2005,9 @@ func (m Model) recalculateLayout() Model {
 
 	// Left Column Layout
 	m.panelHeights[StatusPanel] = statusPanelHeight
+	if m.repoState.InProgress() {
+		m.panelHeights[StatusPanel] += repoStateBannerHeight
+	}
 	remainingHeight := contentHeight - m.panelHeights[StatusPanel]
 
 	if m.focusedPanel == StashPanel {
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gitxtui/gitx/internal/git"
 	zone "github.com/lrstanley/bubblezone"
 )
 
@@ -28,8 +29,9 @@ func (m Model) View() string {
 		finalView = m.renderMainView()
 	}
 
-	// If not in normal mode, render a pop-up on top.
-	if m.mode != modeNormal {
+	// If not in normal mode, render a pop-up on top. The filter of a list
+	// panel is typed in its title instead.
+	if m.mode != modeNormal && m.mode != modeFilter {
 		var popup string
 		switch m.mode {
 		case modeInput:
@@ -38,6 +40,12 @@ func (m Model) View() string {
 			popup = m.renderConfirmPopup()
 		case modeCommit:
 			popup = m.renderCommitPopup()
+		case modeTrailer:
+			popup = m.renderTrailerPopup()
+		case modeMenu:
+			popup = m.renderMenuPopup()
+		case modeAbsorb:
+			popup = m.renderAbsorbPopup()
 		}
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, popup)
 	}
@@ -63,13 +71,29 @@ func (m Model) renderInputPopup() string {
 
 // renderCommitPopup creates the view for the commit message pop-up.
 func (m Model) renderCommitPopup() string {
-	content := lipgloss.JoinVertical(
-		lipgloss.Left,
-		m.theme.ActiveTitle.Render(" Commit Message "),
-		m.textInput.View(),
-		m.descriptionInput.View(),
-		m.theme.InactiveTitle.Render(" (Tab to switch, Enter to save, Esc to cancel) "),
-	)
+	title := m.textInput.Value()
+	header := m.theme.ActiveTitle.Render(" Commit Message ")
+	if m.commitLint.subjectMaxLength > 0 && m.commitLint.mode != commitLintOff {
+		counter := fmt.Sprintf(" %d/%d", len([]rune(title)), m.commitLint.subjectMaxLength)
+		counterStyle := m.theme.LintOK
+		if len([]rune(title)) > m.commitLint.subjectMaxLength {
+			counterStyle = m.theme.LintViolation
+		}
+		header = lipgloss.JoinHorizontal(lipgloss.Left, header, counterStyle.Render(counter))
+	}
+
+	rows := []string{header, m.textInput.View(), m.descriptionInput.View()}
+	if title != "" || m.descriptionInput.Value() != "" {
+		for _, violation := range m.commitLint.lint(buildCommitMessage(title, m.descriptionInput.Value())) {
+			rows = append(rows, m.theme.LintViolation.Render("✗ "+violation))
+		}
+	}
+	hint := " (Tab to switch, Ctrl+R to add trailer, Enter to save, Esc to cancel) "
+	if m.commitLint.blocksSubmit() {
+		hint = " (Tab to switch, Ctrl+R to add trailer, Enter to save once all rules pass, Esc to cancel) "
+	}
+	rows = append(rows, m.theme.InactiveTitle.Render(hint))
+	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
 
 	return lipgloss.NewStyle().
 		Padding(1, 2).
@@ -78,6 +102,247 @@ func (m Model) renderCommitPopup() string {
 		Render(content)
 }
 
+// renderTrailerPopup creates the view for the trailer picker pop-up.
+func (m Model) renderTrailerPopup() string {
+	p := m.trailerPicker
+	var tabs []string
+	for i, kind := range p.kinds {
+		if i == p.kindIndex {
+			tabs = append(tabs, m.theme.ActiveTitle.Render(" "+kind.token+" "))
+		} else {
+			tabs = append(tabs, m.theme.InactiveTitle.Render(" "+kind.token+" "))
+		}
+	}
+
+	rows := []string{
+		m.theme.ActiveTitle.Render(" Add Trailer "),
+		lipgloss.JoinHorizontal(lipgloss.Left, tabs...),
+		p.input.View(),
+	}
+
+	if p.kind().isPerson {
+		start := 0
+		if p.cursor >= trailerPickerMaxItems {
+			start = p.cursor - trailerPickerMaxItems + 1
+		}
+		for i := start; i < len(p.matches) && i < start+trailerPickerMaxItems; i++ {
+			match := p.matches[i]
+			author := p.authors[match.index]
+			if i == p.cursor {
+				rows = append(rows, m.theme.SelectedLine.Render("> "+author))
+			} else {
+				rows = append(rows, "  "+highlightMatches(author, match.positions, m.theme.NormalText, m.theme.FuzzyMatch))
+			}
+		}
+		if len(p.matches) == 0 {
+			rows = append(rows, m.theme.InactiveTitle.Render(" No matching authors "))
+		}
+	}
+
+	if trailer := p.trailer(); trailer != "" {
+		rows = append(rows, "", m.theme.LintOK.Render(trailer))
+	}
+	rows = append(rows, m.theme.InactiveTitle.Render(" (Tab to change trailer, ↑/↓ to select, Enter to insert, Esc to go back) "))
+
+	return lipgloss.NewStyle().
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.ActiveBorder.Style.GetForeground()).
+		Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// renderMenuPopup creates the view for the menu pop-up.
+func (m Model) renderMenuPopup() string {
+	rows := []string{m.theme.ActiveTitle.Render(" " + m.menuTitle + " ")}
+	for i, item := range m.menuItems {
+		if i == m.menuCursor {
+			rows = append(rows, m.theme.SelectedLine.Render("> "+item.label))
+		} else {
+			rows = append(rows, m.theme.NormalText.Render("  "+item.label))
+		}
+	}
+	if m.menuCursor < len(m.menuItems) && m.menuItems[m.menuCursor].detail != "" {
+		rows = append(rows, "", m.menuItems[m.menuCursor].detail)
+	}
+	rows = append(rows, m.theme.InactiveTitle.Render(" (↑/↓ to select, Enter to confirm, Esc to cancel) "))
+
+	return lipgloss.NewStyle().
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.ActiveBorder.Style.GetForeground()).
+		Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// renderAbsorbPopup creates the view for the absorb review list. Ambiguous
+// hunks are marked with "?" and the selected hunk's changes are shown below.
+func (m Model) renderAbsorbPopup() string {
+	r := m.absorbReview
+	rows := []string{m.theme.ActiveTitle.Render(" Absorb staged hunks into fixup commits ")}
+	for i, hunk := range r.plan.Hunks {
+		marker := " "
+		if hunk.Ambiguous {
+			marker = "?"
+		}
+		header := hunk.Header
+		if end := strings.Index(header[2:], "@@"); end >= 0 {
+			header = header[:end+4]
+		}
+		row := fmt.Sprintf("%s %s %s  %s", marker, hunk.Path, header, r.targetLabel(hunk))
+		if i == r.cursor {
+			rows = append(rows, m.theme.SelectedLine.Render("> "+row))
+		} else if hunk.Ambiguous {
+			rows = append(rows, m.theme.LintViolation.Render("  "+row))
+		} else {
+			rows = append(rows, m.theme.NormalText.Render("  "+row))
+		}
+	}
+
+	rows = append(rows, "")
+	lines := r.hunk().Lines
+	if len(lines) > absorbPreviewMaxLines {
+		lines = append(lines[:absorbPreviewMaxLines:absorbPreviewMaxLines], "...")
+	}
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			rows = append(rows, m.theme.DiffAdded.Render(line))
+		case strings.HasPrefix(line, "-"):
+			rows = append(rows, m.theme.DiffRemoved.Render(line))
+		default:
+			rows = append(rows, m.theme.NormalText.Render(line))
+		}
+	}
+	rows = append(rows, "", m.theme.InactiveTitle.Render(fmt.Sprintf(
+		" %d of %d hunks will be absorbed (↑/↓ to select, ←/→ to change target, Enter to absorb, Esc to cancel) ",
+		r.absorbedCount(), len(r.plan.Hunks))))
+
+	return lipgloss.NewStyle().
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.ActiveBorder.Style.GetForeground()).
+		Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// resetModeLabels are the menu labels of the reset modes.
+var resetModeLabels = map[git.ResetMode]string{
+	git.ResetSoft:  "Soft reset (keep index and working tree)",
+	git.ResetMixed: "Mixed reset (keep working tree, unstage changes)",
+	git.ResetHard:  "Hard reset (discard all changes)",
+	git.ResetKeep:  "Keep reset (keep local changes, refuse if they would be lost)",
+}
+
+// renderResetPreview describes what resetting to sha with mode affects: the
+// commits that leave the branch and what happens to local changes.
+func (m Model) renderResetPreview(preview *git.ResetPreview, mode git.ResetMode, sha string) string {
+	var sections []string
+	if len(preview.Commits) > 0 {
+		sections = append(sections, m.renderPreviewList(
+			fmt.Sprintf("%d commit(s) will leave the branch:", len(preview.Commits)), preview.Commits, m.theme.NormalText))
+	} else {
+		sections = append(sections, m.theme.NormalText.Render("No commits will leave the branch."))
+	}
+
+	switch mode {
+	case git.ResetSoft:
+		sections = append(sections, m.theme.LintOK.Render("Index and working tree are kept; the commits' changes become staged."))
+	case git.ResetMixed:
+		if len(preview.Staged) > 0 {
+			sections = append(sections, m.renderPreviewList("Staged changes will be unstaged (not lost):", preview.Staged, m.theme.NormalText))
+		}
+		sections = append(sections, m.theme.LintOK.Render("The working tree is kept; the commits' changes become unstaged."))
+	case git.ResetHard:
+		discarded := mergePreviewChanges(preview.Staged, preview.Unstaged)
+		if len(discarded) > 0 {
+			sections = append(sections, m.renderPreviewList("These uncommitted changes will be discarded:", discarded, m.theme.LintViolation))
+		}
+		if len(preview.Commits) > 0 {
+			sections = append(sections, m.theme.LintViolation.Render("The commits' changes are removed from the working tree."))
+		}
+	case git.ResetKeep:
+		var conflicts []string
+		for _, change := range mergePreviewChanges(preview.Staged, preview.Unstaged) {
+			if _, path, _ := strings.Cut(change, "\t"); containsString(preview.Changed, path) {
+				conflicts = append(conflicts, change)
+			}
+		}
+		if len(conflicts) > 0 {
+			sections = append(sections, m.renderPreviewList(
+				fmt.Sprintf("Will be refused; these local changes touch files that differ in %s:", sha), conflicts, m.theme.LintViolation))
+		} else {
+			sections = append(sections, m.theme.LintOK.Render("Local changes are kept; the commits' changes are removed."))
+		}
+	}
+	return strings.Join(sections, "\n")
+}
+
+// renderPreviewList renders a title followed by at most
+// resetPreviewMaxItems indented items in style.
+func (m Model) renderPreviewList(title string, items []string, style lipgloss.Style) string {
+	rows := []string{m.theme.NormalText.Render(title)}
+	for i, item := range items {
+		if i == resetPreviewMaxItems {
+			rows = append(rows, style.Render(fmt.Sprintf("  … and %d more", len(items)-i)))
+			break
+		}
+		rows = append(rows, style.Render("  "+strings.ReplaceAll(item, "\t", " ")))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// mergePreviewChanges combines staged and unstaged "status\tpath" changes,
+// listing every path once.
+func mergePreviewChanges(staged, unstaged []string) []string {
+	seen := make(map[string]bool)
+	var changes []string
+	for _, change := range append(append([]string{}, staged...), unstaged...) {
+		_, path, _ := strings.Cut(change, "\t")
+		if !seen[path] {
+			seen[path] = true
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// renderRepoStateBanner renders the Status panel banner for an in-progress
+// operation, e.g. " Rebasing feature (step 2 of 5) ".
+func (m Model) renderRepoStateBanner(state git.RepoState) string {
+	return m.theme.StateBanner.Render(" " + strings.ToUpper(state.String()[:1]) + state.String()[1:] + " ")
+}
+
+// renderMergePrediction renders the result of a merge-tree dry run for the
+// Main panel: whether merging branch is clean, and the conflicting hunks.
+func (m Model) renderMergePrediction(prediction *git.MergePrediction, branch string) string {
+	var rows []string
+	if prediction.Clean {
+		rows = append(rows, m.theme.LintOK.Render(fmt.Sprintf("✓ Merging %s into HEAD is clean.", branch)))
+	} else {
+		rows = append(rows, m.theme.LintViolation.Render(fmt.Sprintf("✗ Merging %s into HEAD conflicts in %d file(s):", branch, len(prediction.Conflicts))))
+		for _, conflict := range prediction.Conflicts {
+			rows = append(rows, "", m.theme.GitConflicted.Render(conflict.Path))
+			for _, hunk := range conflict.Hunks {
+				for _, line := range strings.Split(hunk, "\n") {
+					switch {
+					case strings.HasPrefix(line, "<<<<<<<"), strings.HasPrefix(line, "======="),
+						strings.HasPrefix(line, ">>>>>>>"), strings.HasPrefix(line, "|||||||"):
+						rows = append(rows, m.theme.CommitSHA.Render(line))
+					default:
+						rows = append(rows, m.theme.NormalText.Render(line))
+					}
+				}
+			}
+		}
+	}
+	if len(prediction.Messages) > 0 {
+		rows = append(rows, "", m.theme.InactiveTitle.Render(" git merge-tree "))
+		for _, message := range prediction.Messages {
+			rows = append(rows, m.theme.NormalText.Render(message))
+		}
+	}
+	rows = append(rows, "", m.theme.InactiveTitle.Render(fmt.Sprintf(" Nothing was changed. Press %s to show the log again. ", keys.PredictMerge.Help().Key)))
+	return strings.Join(rows, "\n")
+}
+
 // renderConfirmPopup creates the view for the confirmation pop-up.
 func (m Model) renderConfirmPopup() string {
 	content := lipgloss.JoinVertical(
@@ -109,6 +374,16 @@ func (m Model) renderMainView() string {
 		MainPanel: "Main", StatusPanel: "Status", FilesPanel: "Files",
 		BranchesPanel: "Branches", CommitsPanel: "Commits", StashPanel: "Stash", SecondaryPanel: "Secondary",
 	}
+	var mainLabels []string
+	if m.comparison != nil {
+		mainLabels = append(mainLabels, m.comparison.label())
+	}
+	if label := diffOptionsLabel(m.diffOptions); label != "" {
+		mainLabels = append(mainLabels, label)
+	}
+	if len(mainLabels) > 0 {
+		titles[MainPanel] = fmt.Sprintf("Main (%s)", strings.Join(mainLabels, "; "))
+	}
 
 	leftColumn := m.renderPanelColumn(leftpanels, titles, leftSectionWidth)
 	rightColumn := m.renderPanelColumn(rightpanels, titles, rightSectionWidth)
@@ -150,6 +425,15 @@ func (m Model) renderPanel(title string, width, height int, panel Panel) string
 		if len(p.lines) > 0 {
 			formattedTitle = fmt.Sprintf("[%d] %s (%d/%d)", int(panel), title, p.cursor+1, len(p.lines))
 		}
+		if selected := len(p.selection(panel)); selected > 0 {
+			formattedTitle += fmt.Sprintf(" %d selected", selected)
+		}
+		if p.filter != "" || (isFocused && m.mode == modeFilter) {
+			formattedTitle += " /" + p.filter
+			if isFocused && m.mode == modeFilter {
+				formattedTitle += "▌"
+			}
+		}
 	}
 
 	content := p.content
@@ -157,31 +441,27 @@ func (m Model) renderPanel(title string, width, height int, panel Panel) string
 
 	// For selectable panels, render each line individually.
 	if panel == FilesPanel || panel == BranchesPanel || panel == CommitsPanel || panel == StashPanel {
+		selected := make(map[int]bool)
+		for _, i := range p.selectedLines(panel) {
+			selected[i] = true
+		}
 		var builder strings.Builder
 		for i, line := range p.lines {
 			lineID := fmt.Sprintf("%s-line-%d", panel.ID(), i)
 			var finalLine string
 
 			if i == p.cursor && isFocused {
-				var cleanLine string
-				// For the selected line, strip any existing ANSI codes before applying selection style.
-				if panel == FilesPanel {
-					// For files panel, don't show the hidden path in the selection.
-					parts := strings.Split(line, "\t")
-					if len(parts) >= 3 {
-						cleanLine = fmt.Sprintf("%s %s %s", parts[0], parts[1], parts[2])
-					} else {
-						cleanLine = line
-					}
-				} else {
-					cleanLine = stripAnsi(line)
-				}
-
-				cleanLine = strings.ReplaceAll(cleanLine, "\t", "  ") // Also replace tabs
 				selectionStyle := m.theme.SelectedLine.Width(contentWidth)
-				finalLine = selectionStyle.Render(cleanLine)
+				finalLine = selectionStyle.Render(plainLine(panel, line))
+			} else if selected[i] || (panel == CommitsPanel && m.commitMark != "" && strings.Contains(line, "\t"+m.commitMark+"\t")) {
+				// A selected line, or the marked end of a commit range.
+				finalLine = m.theme.MarkedLine.MaxWidth(contentWidth).Render(plainLine(panel, line))
 			} else {
-				styledLine := styleUnselectedLine(line, panel, m.theme)
+				var highlights [][]int
+				if i < len(p.highlights) {
+					highlights = p.highlights[i]
+				}
+				styledLine := styleUnselectedLine(line, panel, m.theme, highlights)
 				finalLine = lipgloss.NewStyle().MaxWidth(contentWidth).Render(styledLine)
 			}
 
@@ -206,6 +486,38 @@ func (m Model) renderPanel(title string, width, height int, panel Panel) string
 	return zone.Mark(panel.ID(), box)
 }
 
+// plainLine returns a line of a list panel without ANSI codes, so that the
+// selection style can be applied to it.
+func plainLine(panel Panel, line string) string {
+	var cleanLine string
+	if panel == FilesPanel {
+		// For files panel, don't show the hidden path in the selection.
+		parts := strings.Split(line, "\t")
+		if len(parts) >= 3 {
+			status := parts[1]
+			if len(parts) >= 7 && status == "" {
+				status = parts[6]
+			}
+			name := parts[2]
+			if len(parts) >= 8 && parts[7] != "" {
+				name = parts[7] + " " + name
+			}
+			cleanLine = fmt.Sprintf("%s %s %s", parts[0], status, name)
+			if len(parts) >= 6 {
+				cleanLine += strings.TrimRight(" "+parts[4]+" "+parts[5], " ")
+			}
+		} else {
+			cleanLine = line
+		}
+	} else if parts := strings.Split(line, "\t"); panel == StashPanel && len(parts) >= 3 {
+		// Don't show the hidden SHA of a stash.
+		cleanLine = stripAnsi(parts[0] + "\t" + parts[1])
+	} else {
+		cleanLine = stripAnsi(line)
+	}
+	return strings.ReplaceAll(cleanLine, "\t", "  ") // Also replace tabs
+}
+
 // renderHelpView renders the full-screen help view.
 func (m Model) renderHelpView() string {
 	showScrollbar := !m.helpViewport.AtTop() || !m.helpViewport.AtBottom()
@@ -241,7 +553,7 @@ func (m Model) renderHelpBar() string {
 
 // renderBox manually constructs a bordered box with a title and an integrated scrollbar.
 func renderBox(title string, titleStyle lipgloss.Style, borderStyle BorderStyle, vp viewport.Model, thumbStyle lipgloss.Style, width, height int, showScrollbar bool) string {
-	contentLines := strings.Split(vp.View(), "\n")
+	contentLines := clipSixelImages(strings.Split(vp.View(), "\n"))
 	contentWidth := width - borderWidth
 	contentHeight := height - titleBarHeight
 	if contentHeight < 0 {
@@ -316,7 +628,7 @@ func (m Model) renderHelpSection(bindings []key.Binding) string {
 }
 
 // styleUnselectedLine parses a raw data line and applies panel-specific styling.
-func styleUnselectedLine(line string, panel Panel, theme Theme) string {
+func styleUnselectedLine(line string, panel Panel, theme Theme, highlights [][]int) string {
 	switch panel {
 	case FilesPanel:
 		parts := strings.Split(line, "\t")
@@ -324,14 +636,40 @@ func styleUnselectedLine(line string, panel Panel, theme Theme) string {
 			return line
 		}
 		prefix, status, path := parts[0], parts[1], parts[2]
+		if len(parts) >= 4 && parts[3] == "" {
+			return prefix + " " + theme.FileSection.Render(path) // A section of the grouped view.
+		}
 
+		if status == "" && len(parts) >= 7 {
+			status = parts[6] // The aggregated status of a collapsed directory.
+		}
 		var styledStatus string
 		if status == "" {
 			styledStatus = "  "
 		} else {
 			styledStatus = styleStatus(status, theme)
 		}
-		return fmt.Sprintf("%s %s %s", prefix, styledStatus, path)
+		path = highlightMatches(path, fieldHighlights(highlights, 0), lipgloss.NewStyle(), theme.FuzzyMatch)
+		if len(parts) >= 8 && parts[7] != "" {
+			// The staging state of a directory.
+			iconStyle := theme.GitStaged
+			if parts[7] == dirUnstagedIcon {
+				iconStyle = theme.GitUnstaged
+			}
+			path = iconStyle.Render(parts[7]) + " " + path
+		}
+		styledLine := fmt.Sprintf("%s %s %s", prefix, styledStatus, path)
+		if len(parts) >= 6 {
+			// Line counts of the staged and unstaged changes, colored like the
+			// two characters of the status.
+			if parts[4] != "" {
+				styledLine += " " + theme.GitStaged.Render(parts[4])
+			}
+			if parts[5] != "" {
+				styledLine += " " + theme.GitUnstaged.Render(parts[5])
+			}
+		}
+		return styledLine
 	case BranchesPanel:
 		parts := strings.SplitN(line, "\t", 2)
 		if len(parts) != 2 {
@@ -339,10 +677,11 @@ func styleUnselectedLine(line string, panel Panel, theme Theme) string {
 		}
 		date, name := parts[0], parts[1]
 		styledDate := theme.BranchDate.Render(date)
-		styledName := theme.NormalText.Render(name)
+		nameStyle := theme.NormalText
 		if strings.Contains(name, "(*)") {
-			styledName = theme.BranchCurrent.Render(name)
+			nameStyle = theme.BranchCurrent
 		}
+		styledName := highlightMatches(name, fieldHighlights(highlights, 0), nameStyle, theme.FuzzyMatch)
 		return lipgloss.JoinHorizontal(lipgloss.Left, styledDate, " ", styledName)
 	case CommitsPanel:
 		parts := strings.SplitN(line, "\t", 4)
@@ -357,27 +696,38 @@ func styleUnselectedLine(line string, panel Panel, theme Theme) string {
 		styledGraph := strings.ReplaceAll(graph, "○", theme.GraphNode.Render("○"))
 
 		// Apply our theme's styles to the other parts.
-		styledSHA := theme.CommitSHA.Render(sha)
-		styledAuthor := theme.CommitAuthor.Render(author)
+		styledSHA := highlightMatches(sha, fieldHighlights(highlights, 0), theme.CommitSHA, theme.FuzzyMatch)
+		authorStyle := theme.CommitAuthor
 		if strings.HasPrefix(strings.ToLower(subject), "merge") {
-			styledAuthor = theme.CommitMerge.Render(author)
+			authorStyle = theme.CommitMerge
 		}
+		styledAuthor := highlightMatches(author, fieldHighlights(highlights, 1), authorStyle, theme.FuzzyMatch)
+		styledSubject := highlightMatches(subject, fieldHighlights(highlights, 2), lipgloss.NewStyle(), theme.FuzzyMatch)
 
-		final := lipgloss.JoinHorizontal(lipgloss.Left, styledSHA, " ", styledAuthor, " ", subject)
+		final := lipgloss.JoinHorizontal(lipgloss.Left, styledSHA, " ", styledAuthor, " ", styledSubject)
 		return fmt.Sprintf("%s %s", styledGraph, final)
 	case StashPanel:
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) != 2 {
+		parts := strings.Split(line, "\t")
+		if len(parts) < 2 {
 			return line
 		}
 		name, message := parts[0], parts[1]
-		styledName := theme.StashName.Render(name)
-		styledMessage := theme.StashMessage.Render(message)
+		styledName := highlightMatches(name, fieldHighlights(highlights, 0), theme.StashName, theme.FuzzyMatch)
+		styledMessage := highlightMatches(message, fieldHighlights(highlights, 1), theme.StashMessage, theme.FuzzyMatch)
 		return lipgloss.JoinHorizontal(lipgloss.Left, styledName, " ", styledMessage)
 	}
 	return line
 }
 
+// fieldHighlights returns the matched rune positions in a column of a
+// filtered line, or nil when the line is not filtered.
+func fieldHighlights(highlights [][]int, column int) []int {
+	if column < len(highlights) {
+		return highlights[column]
+	}
+	return nil
+}
+
 // styleStatus takes a 2-character git status code and returns a styled string.
 func styleStatus(status string, theme Theme) string {
 	if len(status) < 2 {
@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gitxtui/gitx/internal/git"
+)
+
+// diffSpan is a range of runes [start, end) within a diff line.
+type diffSpan struct {
+	start, end int
+}
+
+// diffToken is a word, or the text between words, of a diff line.
+type diffToken struct {
+	text       string
+	start, end int // Rune offsets in the line.
+}
+
+// wordDiffConfig controls the intra-line highlighting of changed words.
+type wordDiffConfig struct {
+	wordRegex    *regexp.Regexp // nil disables word diffs.
+	maxHunkLines int
+}
+
+// loadWordDiffConfig reads the word diff settings from git config:
+// `gitx.diff.wordRegex` (use "." for character-level diffs) and
+// `gitx.diff.wordDiffMaxHunkLines` (0 turns highlighting off).
+func loadWordDiffConfig(gc *git.GitCommands) wordDiffConfig {
+	config := wordDiffConfig{
+		wordRegex:    regexp.MustCompile(defaultWordDiffRegex),
+		maxHunkLines: defaultWordDiffMaxHunkLines,
+	}
+	if value, err := gc.GetConfigValue("gitx.diff.wordRegex"); err == nil && value != "" {
+		if re, err := regexp.Compile(value); err == nil {
+			config.wordRegex = re
+		}
+	}
+	if value, err := gc.GetConfigValue("gitx.diff.wordDiffMaxHunkLines"); err == nil && value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			config.maxHunkLines = n
+		}
+	}
+	return config
+}
+
+// hunkSpans pairs the removed and added lines of every change in a hunk, in
+// order, and returns the changed spans of each paired line by its index in
+// lines. Hunks larger than maxHunkLines are not highlighted.
+func (c wordDiffConfig) hunkSpans(lines []git.DiffLine) map[int][]diffSpan {
+	if c.wordRegex == nil || len(lines) > c.maxHunkLines {
+		return nil
+	}
+
+	spans := make(map[int][]diffSpan)
+	var removed, added []int
+	flush := func() {
+		for i := 0; i < len(removed) && i < len(added); i++ {
+			oldSpans, newSpans := c.changedSpans(expandTabs(lines[removed[i]].Content), expandTabs(lines[added[i]].Content))
+			spans[removed[i]] = oldSpans
+			spans[added[i]] = newSpans
+		}
+		removed, added = nil, nil
+	}
+	for i, line := range lines {
+		switch line.Kind {
+		case git.DiffRemoved:
+			removed = append(removed, i)
+		case git.DiffAdded:
+			added = append(added, i)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return spans
+}
+
+// changedSpans returns the spans of oldLine and newLine that are not part of
+// their longest common token subsequence. Lines with nothing in common get
+// no spans, since highlighting all of them would add no information.
+func (c wordDiffConfig) changedSpans(oldLine, newLine string) (oldSpans, newSpans []diffSpan) {
+	oldTokens := tokenize(c.wordRegex, oldLine)
+	newTokens := tokenize(c.wordRegex, newLine)
+	if len(oldTokens)*len(newTokens) > wordDiffMaxTokenPairs {
+		return nil, nil
+	}
+
+	// lcs[i][j] is the length of the common subsequence of oldTokens[i:]
+	// and newTokens[j:].
+	lcs := make([][]int, len(oldTokens)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newTokens)+1)
+	}
+	for i := len(oldTokens) - 1; i >= 0; i-- {
+		for j := len(newTokens) - 1; j >= 0; j-- {
+			if oldTokens[i].text == newTokens[j].text {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	if lcs[0][0] == 0 || !hasWordInCommon(oldTokens, newTokens, lcs) {
+		return nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(oldTokens) || j < len(newTokens) {
+		switch {
+		case i < len(oldTokens) && j < len(newTokens) && oldTokens[i].text == newTokens[j].text:
+			i++
+			j++
+		case j == len(newTokens) || (i < len(oldTokens) && lcs[i+1][j] >= lcs[i][j+1]):
+			oldSpans = appendSpan(oldSpans, oldTokens[i])
+			i++
+		default:
+			newSpans = appendSpan(newSpans, newTokens[j])
+			j++
+		}
+	}
+	return oldSpans, newSpans
+}
+
+// hasWordInCommon reports whether the common subsequence contains more than
+// whitespace, so that lines sharing only spaces are not highlighted.
+func hasWordInCommon(oldTokens, newTokens []diffToken, lcs [][]int) bool {
+	i, j := 0, 0
+	for i < len(oldTokens) && j < len(newTokens) {
+		switch {
+		case oldTokens[i].text == newTokens[j].text:
+			if strings.TrimSpace(oldTokens[i].text) != "" {
+				return true
+			}
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return false
+}
+
+// appendSpan adds the span of token, merging it with the last span when
+// they touch.
+func appendSpan(spans []diffSpan, token diffToken) []diffSpan {
+	if n := len(spans); n > 0 && spans[n-1].end == token.start {
+		spans[n-1].end = token.end
+		return spans
+	}
+	return append(spans, diffSpan{start: token.start, end: token.end})
+}
+
+// tokenize splits s into the matches of wordRegex and the text between them.
+func tokenize(wordRegex *regexp.Regexp, s string) []diffToken {
+	var tokens []diffToken
+	runeOffset := func(byteOffset int) int {
+		return len([]rune(s[:byteOffset]))
+	}
+	add := func(from, to int) {
+		if from < to {
+			tokens = append(tokens, diffToken{text: s[from:to], start: runeOffset(from), end: runeOffset(to)})
+		}
+	}
+	last := 0
+	for _, match := range wordRegex.FindAllStringIndex(s, -1) {
+		add(last, match[0])
+		add(match[0], match[1])
+		last = match[1]
+	}
+	add(last, len(s))
+	return tokens
+}
+
+// expandTabs replaces tabs with spaces so that widths and spans line up.
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", strings.Repeat(" ", diffTabWidth))
+}